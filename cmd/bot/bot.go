@@ -1,68 +1,116 @@
 package main
 
 import (
-	"context"
-	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
-	"regexp"
-	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/buckley-w-david/anibot/pkg/anilist"
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	cacheSize = 512
+	// cacheTTL is how long lookups are cached. pkg/anilist keeps searches and airing schedules for less.
+	cacheTTL = 6 * time.Hour
+)
+
 var (
 	discord *discordgo.Session
-
-	re *regexp.Regexp
+	ani     *anilist.Client
 )
 
 func init() {
 	SetupSharedOptions()
-	flag.Parse()
-
-	re = regexp.MustCompile(`{(?P<ANIME>.*?)}|<(?P<MANGA>.*?)>`)
 }
 
 func main() {
+	// Parsed here rather than in init, so the test binary's own flags aren't mistaken for ours.
+	flag.Parse()
 	botToken, err := Token.OrEnv()
 	if err != nil {
 		fmt.Println(MissingToken)
 		return
 	}
 
+	var cache anilist.Cache = anilist.NewMemoryCache(cacheSize)
+	if dir, err := CacheDir.OrEnv(); err == nil {
+		cache, err = anilist.NewFileCache(dir)
+		if err != nil {
+			fmt.Println("Error creating response cache: ", err)
+			return
+		}
+	}
+
+	if path, err := Buttons.OrEnv(); err == nil {
+		if err := buttons.load(path); err != nil {
+			fmt.Println("Error loading buttons: ", err)
+			return
+		}
+	}
+
+	if path, err := Subscriptions.OrEnv(); err == nil {
+		if err := subscriptions.load(path); err != nil {
+			fmt.Println("Error loading subscriptions: ", err)
+			return
+		}
+	}
+
+	if path, err := Settings.OrEnv(); err == nil {
+		if err := settings.load(path); err != nil {
+			fmt.Println("Error loading settings: ", err)
+			return
+		}
+	}
+
+	endpoint, _ := Endpoint.OrEnv()
+	ani = anilist.NewClient(
+		anilist.WithEndpoint(endpoint),
+		anilist.WithUserAgent("anibot"),
+		anilist.WithCache(cache, cacheTTL),
+	)
+
 	// Create a new Discord session using the provided bot token.
 	discord, err = discordgo.New("Bot " + botToken)
 	if err != nil {
 		fmt.Println("Error creating Discord session: ", err)
 		return
 	}
+	// Inline requests and bot commands need to read message content, which is a privileged intent.
+	discord.Identify.Intents = discordgo.IntentsAllWithoutPrivileged | discordgo.IntentsMessageContent
+
 	// Register ready as a callback for the ready events.
 	discord.AddHandler(func(discord *discordgo.Session, ready *discordgo.Ready) {
-		game := discordgo.Game{
+		activity := discordgo.Activity{
 			Name: "anilist",
-			Type: discordgo.GameTypeWatching,
+			Type: discordgo.ActivityTypeWatching,
 		}
 
 		status := discordgo.UpdateStatusData{
-			Game: &game,
-			AFK:  false,
+			Activities: []*discordgo.Activity{&activity},
+			AFK:        false,
 		}
 		err := discord.UpdateStatusComplex(status)
 
 		if err != nil {
 			fmt.Println("Error attempting to set my status")
 		}
+
+		err = registerCommands(discord)
+		if err != nil {
+			fmt.Println("Error registering slash commands: ", err)
+		}
 	})
 
 	// Register messageCreate as a callback for the messageCreate events.
 	discord.AddHandler(messageCreate)
-	// discord.AddHandler(reaction)
+	// Slash commands, their autocompletion, and follow-up buttons all arrive as interactions.
+	discord.AddHandler(interactionCreate)
 
 	// Open the websocket and begin listening.
 	err = discord.Open()
@@ -72,17 +120,17 @@ func main() {
 	}
 	defer discord.Close()
 
+	go watchAiring(discord)
+
 	// Wait here until CTRL-C or other term signal is received.
 	fmt.Println("Anibot is now running.  Press CTRL-C to exit.")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
 	<-sc
-}
 
-// func reaction(s *discordgo.Session, m *discordgo.MessageReactionAdd) {
-// 	fmt.Println(m.Emoji)
-// 	spew.Dump(m.Emoji)
-// }
+	stats := ani.CacheStats()
+	fmt.Printf("Response cache: %d hits, %d misses\n", stats.Hits, stats.Misses)
+}
 
 func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Ignore all messages created by the bot itself
@@ -90,144 +138,52 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
+	guildSettings := settings.get(m.GuildID)
+
 	// Service direct bot commands
-	if strings.HasPrefix(m.Content, "!anibot ") {
-		request := strings.TrimPrefix(m.Content, "!anibot ")
-		err := botCommand(s, m.ChannelID, request)
+	prefix := guildSettings.prefix()
+	if m.Content == prefix || strings.HasPrefix(m.Content, prefix+" ") {
+		request := strings.TrimPrefix(m.Content, prefix)
+		err := botCommand(s, m.GuildID, m.ChannelID, m.Author.ID, request)
 		if err != nil {
 			fmt.Println(err)
 		}
 		return
 	}
 
-	// Parse message for any implicit anime/manga requests
-	ctx := context.Background()
-	matches := re.FindAllStringSubmatch(m.Content, -1)
-	for i := range matches {
-		j := i
-		// TODO: This doesn't really need to be a local function, factor out into a real one
-		go func() {
-			var requestType anilist.MediaType
-			var mediaName string
-			match := matches[j]
-			if match[1] != "" {
-				requestType = anilist.ANIME
-				mediaName = match[1]
-			} else if match[2] != "" {
-				requestType = anilist.MANGA
-				mediaName = match[2]
-			} else {
-				fmt.Println("Weird match: ", match)
-				return
-			}
-
-			// See if we can get an exact title match
-			query := anilist.MediaQuery{
-				Title:      mediaName,
-				Type:       requestType.String(),
-				Sort:       []string{"SEARCH_MATCH"},
-				MaxResults: 3,
-			}
-			potentials, err := anilist.MediaFromMediaQuery(ctx, query)
-			if err != nil {
-				fmt.Println("Error getting Media", err)
-				return
-			}
-			for _, potential := range potentials {
-				if potential.Title.Romaji == mediaName || potential.Title.English == mediaName {
-					go Send(s, m.ChannelID, potential)
-					return
-				}
-			}
-
-			// If not just grab the most popular one
-			query = anilist.MediaQuery{
-				Title:      mediaName,
-				Type:       requestType.String(),
-				Sort:       []string{"POPULARITY_DESC"},
-				MaxResults: 1,
-			}
-			media, err := anilist.MediaFromMediaQuery(ctx, query)
-			if err != nil {
-				fmt.Println("Error getting Media", err)
-				return
-			}
-
-			if len(media) > 0 {
-				go Send(s, m.ChannelID, media[0])
-			}
-		}()
+	// Parse message for any implicit anime/manga/character/staff requests
+	for _, request := range parseInline(m.Content, guildSettings.syntaxes()) {
+		go inlineLookup(s, m.ChannelID, m.Author.ID, guildSettings, request)
 	}
 }
 
-func botCommand(s *discordgo.Session, channel string, request string) error {
-	r := csv.NewReader(strings.NewReader(request))
-	r.Comma = ' ' // space
-	fields, err := r.Read()
-	if err != nil {
-		return err
-	}
-	command := fields[0]
-	args := fields[1:len(fields)]
-
-	var queryType string
-	if strings.ToLower(args[0]) == "manga" {
-		queryType = "MANGA"
-		args = args[1:len(args)]
-	} else if strings.ToLower(args[0]) == "anime" {
-		queryType = "ANIME"
-		args = args[1:len(args)]
-	} else {
-		queryType = ""
+// reportError logs err, and lets the channel know when it's something they can act on.
+func reportError(s *discordgo.Session, channel string, err error) {
+	fmt.Println(err)
+
+	if reply := userMessage(err); reply != "" {
+		s.ChannelMessageSend(channel, reply)
 	}
+}
 
-	var media anilist.Media
-	switch command {
-	case "id":
-		for _, id := range args {
-			i, err := strconv.Atoi(id)
-			if err != nil {
-				return err
-			}
-
-			ctx := context.Background()
-			media, err = anilist.MediaFromMediaID(ctx, i)
-			if err != nil {
-				return err
-			}
-		}
-	case "title":
-		for _, title := range args {
-			ctx := context.Background()
-			medias, err := anilist.MediaFromMediaQuery(ctx, anilist.MediaQuery{Title: title, Type: queryType, MaxResults: 1})
-			if err != nil {
-				return err
-			}
-			media = medias[0]
-			Send(s, channel, media)
-		}
-	case "person":
-		for _, name := range args {
-			ctx := context.Background()
-			medias, err := anilist.MediaFromPersonQuery(ctx, anilist.PersonQuery{Name: name, Type: queryType, MaxResults: 1})
-			if err != nil {
-				return err
-			}
-			media = medias[0]
-			Send(s, channel, media)
-		}
-	case "studio":
-		for _, name := range args {
-			ctx := context.Background()
-			medias, err := anilist.MediaFromStudioQuery(ctx, anilist.StudioQuery{Name: name, MaxResults: 1})
-			if err != nil {
-				return err
-			}
-			media = medias[0]
-			Send(s, channel, media)
-		}
-	default:
-		fmt.Println("default")
+// userMessage is what we tell users about err, or "" if it's nothing they can act on.
+func userMessage(err error) string {
+	var rateLimited *anilist.RateLimitError
+	switch {
+	case errors.As(err, &rateLimited):
+		return fmt.Sprintf("AniList is throttling us, try again in %s", rateLimited.RetryAfter.Round(time.Second))
+	case errors.Is(err, anilist.ErrRateLimited):
+		return "AniList is throttling us, try again in a minute."
+	case errors.Is(err, errAdult):
+		return "That's an adult title, and they're turned off in this server."
+	case errors.Is(err, anilist.ErrNotFound):
+		return "AniList doesn't have anything matching that."
+	case errors.Is(err, anilist.ErrInvalidQuery):
+		return "AniList didn't understand that request, try rewording it."
+	case errors.Is(err, anilist.ErrNetwork):
+		return "I couldn't reach AniList, try again in a bit."
+	case errors.Is(err, anilist.ErrUpstream):
+		return "AniList is having trouble right now, try again later."
 	}
-	return nil
+	return ""
 }
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// Discord allows at most 5 buttons per row, and 5 rows per message.
+	buttonsPerRow = 5
+	maxButtonRows = 5
+)
+
+// followUp is a button on a media embed that posts more about one of its credits.
+type followUp struct {
+	Label string `json:"label"`
+	Emoji string `json:"emoji"`
+	// Kind is "person", "studio" or "cast", and decides how TargetID is looked up.
+	Kind     string `json:"kind"`
+	TargetID int    `json:"target_id"`
+	// Role distinguishes buttons that would otherwise point at the same target.
+	Role string `json:"role"`
+}
+
+func (f followUp) customID() string {
+	return fmt.Sprintf("%s:%s:%d", f.Role, f.Kind, f.TargetID)
+}
+
+// FollowUps lists the follow-up buttons that should accompany the embed for media: one for each key staff member
+// in groups, one for its cast if it has any main characters, and one for each studio.
+func FollowUps(media anilist.Media, groups []anilist.RoleGroup) []followUp {
+	var followUps []followUp
+
+	listed := make(map[int]bool)
+	for _, group := range groups {
+		for _, credit := range keyStaff(media, group) {
+			// Someone credited in several groups, like a director who also wrote the script, only needs one button.
+			if listed[credit.Staff.ID] {
+				continue
+			}
+			listed[credit.Staff.ID] = true
+			followUps = append(followUps, followUp{
+				Label:    staffName(credit.Staff),
+				Emoji:    RoleEmojis[group],
+				Kind:     "person",
+				TargetID: credit.Staff.ID,
+				Role:     "staff",
+			})
+		}
+	}
+	if len(media.Characters.Edges) > 0 {
+		followUps = append(followUps, followUp{Label: "Cast", Emoji: CastEmoji, Kind: "cast", TargetID: media.ID, Role: "cast"})
+	}
+	for i, edge := range media.Studios.Edges {
+		followUps = append(followUps, followUp{
+			Label:    edge.Node.Name,
+			Emoji:    StudioEmojis[i%len(StudioEmojis)],
+			Kind:     "studio",
+			TargetID: edge.Node.ID,
+			Role:     "studio" + strconv.Itoa(i),
+		})
+	}
+
+	if len(followUps) > buttonsPerRow*maxButtonRows {
+		followUps = followUps[:buttonsPerRow*maxButtonRows]
+	}
+	return followUps
+}
+
+// CastFollowUps lists a button for each voice actor in cast, to look up the other roles they've had.
+func CastFollowUps(cast []anilist.CastMember) []followUp {
+	var followUps []followUp
+	listed := make(map[int]bool)
+	for _, member := range cast {
+		for _, va := range append(member.Japanese, member.English...) {
+			if listed[va.ID] {
+				continue
+			}
+			listed[va.ID] = true
+			followUps = append(followUps, followUp{Label: staffName(va), Emoji: VoiceActorEmoji, Kind: "person", TargetID: va.ID, Role: "va"})
+		}
+	}
+
+	if len(followUps) > buttonsPerRow*maxButtonRows {
+		followUps = followUps[:buttonsPerRow*maxButtonRows]
+	}
+	return followUps
+}
+
+// Components lays follow-up buttons out into action rows.
+func Components(followUps []followUp) []discordgo.MessageComponent {
+	var rows []discordgo.MessageComponent
+	for start := 0; start < len(followUps); start += buttonsPerRow {
+		end := start + buttonsPerRow
+		if end > len(followUps) {
+			end = len(followUps)
+		}
+
+		var row discordgo.ActionsRow
+		for _, f := range followUps[start:end] {
+			row.Components = append(row.Components, discordgo.Button{
+				Label:    f.Label,
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: f.Emoji},
+				CustomID: f.customID(),
+			})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// button is a follow-up button that was attached to a message we sent.
+type button struct {
+	MessageID string `json:"message_id"`
+	ChannelID string `json:"channel_id"`
+	followUp
+	Expires time.Time `json:"expires"`
+	Used    bool      `json:"used"`
+}
+
+// buttonRegistry remembers which buttons are live, so each one only works once and only until it expires.
+// If it has a path, the registry is written there on every change so buttons outlive a restart.
+type buttonRegistry struct {
+	mu      sync.Mutex
+	path    string
+	buttons map[string]*button
+}
+
+var buttons = buttonRegistry{buttons: make(map[string]*button)}
+
+func buttonKey(messageID string, customID string) string {
+	return messageID + "/" + customID
+}
+
+func (r *buttonRegistry) register(sent *discordgo.Message, followUps []followUp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, b := range r.buttons {
+		if now.After(b.Expires) {
+			delete(r.buttons, key)
+		}
+	}
+
+	for _, f := range followUps {
+		r.buttons[buttonKey(sent.ID, f.customID())] = &button{
+			MessageID: sent.ID,
+			ChannelID: sent.ChannelID,
+			followUp:  f,
+			Expires:   now.Add(expirationDelay),
+		}
+	}
+	r.save()
+}
+
+// claim marks a button as used, returning false if it has already been used, has expired, or is unknown.
+func (r *buttonRegistry) claim(messageID string, customID string) (*button, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.buttons[buttonKey(messageID, customID)]
+	if !ok || b.Used || time.Now().After(b.Expires) {
+		return nil, false
+	}
+	b.Used = true
+	r.save()
+	return b, true
+}
+
+// load restores the buttons saved at path, and keeps saving changes there from now on.
+// A missing file is not an error, it just means there is nothing to restore.
+func (r *buttonRegistry) load(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.path = path
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var saved []*button
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, b := range saved {
+		if now.Before(b.Expires) {
+			r.buttons[buttonKey(b.MessageID, b.customID())] = b
+		}
+	}
+	return nil
+}
+
+// save writes the registry to its path. The caller must hold r.mu.
+func (r *buttonRegistry) save() {
+	if r.path == "" {
+		return
+	}
+
+	saved := make([]*button, 0, len(r.buttons))
+	for _, b := range r.buttons {
+		saved = append(saved, b)
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		fmt.Println("Error saving buttons: ", err)
+		return
+	}
+
+	// Write to a temporary file first so a crash mid-write doesn't lose every button.
+	tmp := r.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		fmt.Println("Error saving buttons: ", err)
+		return
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		fmt.Println("Error saving buttons: ", err)
+	}
+}
+
+// buttonPressed services a click on one of the follow-up buttons attached by Send.
+func buttonPressed(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	b, ok := buttons.claim(i.Message.ID, customID)
+	if !ok {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "This button has already been used or has expired.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Disable the pressed button so everyone can see it's been used.
+	for _, row := range i.Message.Components {
+		actions, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actions.Components {
+			if btn, ok := component.(*discordgo.Button); ok && btn.CustomID == customID {
+				btn.Disabled = true
+			}
+		}
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     i.Message.Embeds,
+			Components: i.Message.Components,
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	ctx := context.Background()
+	switch b.Kind {
+	case "person":
+		staff, err := ani.StaffFromPersonID(ctx, b.TargetID)
+		if err != nil {
+			reportError(s, b.ChannelID, err)
+			return
+		}
+		SendStaff(s, Channel(b.ChannelID), staff)
+	case "studio":
+		err := SendPage(s, Channel(b.ChannelID), "studio", strconv.Itoa(b.TargetID), 1)
+		if err != nil {
+			reportError(s, b.ChannelID, err)
+		}
+	case "cast":
+		if err := SendCast(s, Channel(b.ChannelID), b.TargetID); err != nil {
+			reportError(s, b.ChannelID, err)
+		}
+	default:
+		fmt.Printf("Unknown button kind %q\n", b.Kind)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func newRegistry() *buttonRegistry {
+	return &buttonRegistry{buttons: make(map[string]*button)}
+}
+
+var (
+	director = followUp{Label: "Shinichiro Watanabe", Kind: "person", TargetID: 1, Role: "staff"}
+	studio   = followUp{Label: "Sunrise", Kind: "studio", TargetID: 2, Role: "studio0"}
+)
+
+func TestButtonClaim(t *testing.T) {
+	r := newRegistry()
+	r.register(&discordgo.Message{ID: "m1", ChannelID: "c1"}, []followUp{director, studio})
+
+	b, ok := r.claim("m1", director.customID())
+	if !ok {
+		t.Fatal("a new button couldn't be claimed")
+	}
+	if b.ChannelID != "c1" || b.Kind != "person" || b.TargetID != 1 {
+		t.Errorf("claimed %+v", b)
+	}
+	if _, ok := r.claim("m1", director.customID()); ok {
+		t.Error("a button was claimed twice")
+	}
+	if _, ok := r.claim("m1", studio.customID()); !ok {
+		t.Error("claiming one button used up another on the same message")
+	}
+	if _, ok := r.claim("m2", studio.customID()); ok {
+		t.Error("a button on a message it wasn't registered for was claimed")
+	}
+	if _, ok := r.claim("m1", "staff:person:99"); ok {
+		t.Error("an unknown button was claimed")
+	}
+}
+
+func TestButtonExpiry(t *testing.T) {
+	r := newRegistry()
+	r.register(&discordgo.Message{ID: "old"}, []followUp{director})
+	r.buttons[buttonKey("old", director.customID())].Expires = time.Now().Add(-time.Minute)
+
+	if _, ok := r.claim("old", director.customID()); ok {
+		t.Error("an expired button was claimed")
+	}
+
+	// Registering more buttons clears out the expired ones.
+	r.register(&discordgo.Message{ID: "new"}, []followUp{studio})
+	if _, ok := r.buttons[buttonKey("old", director.customID())]; ok {
+		t.Error("an expired button was kept")
+	}
+	if b := r.buttons[buttonKey("new", studio.customID())]; b == nil || time.Until(b.Expires) < expirationDelay-time.Minute {
+		t.Errorf("a new button should last %s, got %+v", expirationDelay, b)
+	}
+}
+
+func TestButtonsOutliveRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buttons.json")
+
+	r := newRegistry()
+	if err := r.load(path); err != nil {
+		t.Fatalf("a missing file should load as empty, got %v", err)
+	}
+	r.register(&discordgo.Message{ID: "m1", ChannelID: "c1"}, []followUp{director, studio})
+	r.claim("m1", director.customID())
+	r.buttons[buttonKey("m1", studio.customID())].Expires = time.Now().Add(-time.Minute)
+	r.register(&discordgo.Message{ID: "m2", ChannelID: "c2"}, []followUp{studio})
+
+	restarted := newRegistry()
+	if err := restarted.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := restarted.claim("m1", director.customID()); ok {
+		t.Error("a used button could be claimed again after a restart")
+	}
+	if _, ok := restarted.claim("m1", studio.customID()); ok {
+		t.Error("an expired button was restored")
+	}
+	b, ok := restarted.claim("m2", studio.customID())
+	if !ok {
+		t.Fatal("a live button wasn't restored")
+	}
+	if b.ChannelID != "c2" || b.Label != "Sunrise" || b.Kind != "studio" || b.TargetID != 2 {
+		t.Errorf("restored %+v", b)
+	}
+
+	// The claim after the restart is saved too.
+	again := newRegistry()
+	if err := again.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := again.claim("m2", studio.customID()); ok {
+		t.Error("a button claimed after a restart could be claimed again")
+	}
+}
+
+func TestButtonsLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buttons.json")
+	if err := ioutil.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := newRegistry().load(path); err == nil {
+		t.Error("expected an error loading a corrupt file")
+	}
+}
+
+func TestButtonsLoadSkipsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buttons.json")
+	saved := []*button{
+		{MessageID: "m1", followUp: director, Expires: time.Now().Add(-time.Minute)},
+		{MessageID: "m1", followUp: studio, Expires: time.Now().Add(time.Hour)},
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRegistry()
+	if err := r.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.buttons) != 1 {
+		t.Errorf("expected only the live button to be restored, got %d", len(r.buttons))
+	}
+	if _, ok := r.claim("m1", studio.customID()); !ok {
+		t.Error("the live button wasn't restored")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"flag"
 	"os"
 	"strings"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
 )
 
 type CliOption struct {
@@ -33,8 +35,13 @@ func (cmd CliOption) OrEnv() (value string, err error) {
 }
 
 var (
-	Token   CliOption
-	Buttons CliOption
+	Token    CliOption
+	Buttons  CliOption
+	Endpoint CliOption
+	CacheDir CliOption
+
+	Subscriptions CliOption
+	Settings      CliOption
 )
 
 func init() {
@@ -44,7 +51,15 @@ func init() {
 func SetupSharedOptions() {
 	Token = CliOption{Name: "token", Short: "t", Description: "Bot Token"}
 	Buttons = CliOption{Name: "buttons", Short: "b", Description: "Buttons path"}
+	Endpoint = CliOption{Name: "anilist_endpoint", Short: "e", DefaultValue: anilist.DefaultEndpoint, Description: "AniList GraphQL endpoint"}
+	CacheDir = CliOption{Name: "cache", Short: "c", Description: "Response cache directory (in-memory if unset)"}
+	Subscriptions = CliOption{Name: "subscriptions", Short: "s", Description: "Followed media path"}
+	Settings = CliOption{Name: "settings", Short: "g", Description: "Server settings path"}
 
 	Token.StringVar()
 	Buttons.StringVar()
+	Endpoint.StringVar()
+	CacheDir.StringVar()
+	Subscriptions.StringVar()
+	Settings.StringVar()
 }
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+const commandPrefix = "!anibot"
+
+// commandContext is what a bot command gets to work with.
+type commandContext struct {
+	s       *discordgo.Session
+	guild   string
+	channel string
+	author  string
+	// settings are the settings of the guild the command was used in.
+	settings guildSettings
+
+	// args are the space separated arguments, with anything in double quotes kept together.
+	args []string
+	// raw is everything after the command name, for commands with their own idea of quoting.
+	raw string
+	// mediaType is anime or manga when the command takes a media type and the arguments started with one,
+	// and otherwise the guild's default.
+	mediaType anilist.MediaType
+}
+
+// reply sends a plain message back to the channel the command came from.
+func (c *commandContext) reply(format string, a ...interface{}) error {
+	_, err := c.s.ChannelMessageSend(c.channel, fmt.Sprintf(format, a...))
+	return err
+}
+
+// command is something that can follow the prefix in a message.
+type command struct {
+	name    string
+	aliases []string
+	// args is the argument spec shown in usage, e.g. "[anime|manga] <title>...".
+	args string
+	// minArgs is how many arguments the command needs before it's worth running.
+	minArgs int
+	// mediaType is whether the command accepts a leading anime or manga argument.
+	mediaType bool
+	// summary is shown in the command list, and help in the command's own help, if it has more to say.
+	// {prefix} in help stands for the guild's prefix.
+	summary string
+	help    string
+	run     func(c *commandContext) error
+}
+
+// usage shows how to use the command in a guild whose commands start with prefix.
+func (cmd *command) usage(prefix string) string {
+	if cmd.args == "" {
+		return fmt.Sprintf("`%s %s`", prefix, cmd.name)
+	}
+	return fmt.Sprintf("`%s %s %s`", prefix, cmd.name, cmd.args)
+}
+
+// usageError is returned by a command that was given arguments it can't use. The reason is shown
+// along with the command's usage.
+type usageError struct {
+	reason string
+}
+
+func (e usageError) Error() string {
+	return e.reason
+}
+
+// notFoundError is returned by a command that looked for something and came up empty.
+type notFoundError struct {
+	query string
+}
+
+func (e notFoundError) Error() string {
+	return fmt.Sprintf("no results for %q", e.query)
+}
+
+// commands are listed by help in this order.
+var (
+	commands       []*command
+	commandsByName map[string]*command
+)
+
+func init() {
+	commands = []*command{
+		{
+			name:    "help",
+			args:    "[command]",
+			summary: "List the commands, or explain one of them.",
+			run:     helpCommand,
+		},
+		{
+			name:      "title",
+			args:      "[anime|manga] <title>...",
+			minArgs:   1,
+			mediaType: true,
+			summary:   "Look up media by title.",
+			help:      "Each argument is looked up separately, so put titles with spaces in quotes: `{prefix} title manga \"Vinland Saga\"`.",
+			run:       titleCommand,
+		},
+		{
+			name:      "cast",
+			args:      "[anime|manga] <title>...",
+			minArgs:   1,
+			mediaType: true,
+			summary:   "List the main characters of a title and who voices them.",
+			run:       castCommand,
+		},
+		{
+			name:    "id",
+			args:    "<anilist id>...",
+			minArgs: 1,
+			summary: "Look up media by its AniList ID.",
+			run:     idCommand,
+		},
+		{
+			name:      "search",
+			args:      "[anime|manga] <title>",
+			minArgs:   1,
+			mediaType: true,
+			summary:   "List everything matching a title, and pick the one you meant.",
+			run: func(c *commandContext) error {
+				return searchCommand(c.s, c.channel, c.author, c.mediaType, strings.Join(c.args, " "))
+			},
+		},
+		{
+			name:    "find",
+			args:    "<filters>",
+			minArgs: 1,
+			summary: "Discover media with filters, e.g. `genre:action year:2019 score>80 sort:popular`.",
+			help: "Filters are " + strings.Join(findFilterNames(), ", ") + ". " +
+				"Any other words are searched for in titles, values with spaces go in quotes (`genre:\"slice of life\"`), " +
+				"and `-genre:horror` excludes a genre. year, score and popularity can be compared with >, >=, < and <=.",
+			run: func(c *commandContext) error {
+				return findCommand(c.s, c.channel, c.author, c.raw)
+			},
+		},
+		{
+			name:      "person",
+			aliases:   []string{"staff"},
+			args:      "[anime|manga] <name>...",
+			minArgs:   1,
+			mediaType: true,
+			summary:   "Look up a staff member's profile.",
+			help:      "Giving a media type only lists their work on that kind of media.",
+			run:       personCommand,
+		},
+		{
+			name:    "studio",
+			args:    "<name>...",
+			minArgs: 1,
+			summary: "Look up a studio and what it has made.",
+			run:     studioCommand,
+		},
+		{
+			name:    "character",
+			aliases: []string{"char"},
+			args:    "<name>...",
+			minArgs: 1,
+			summary: "Look up a character.",
+			run:     characterCommand,
+		},
+		{
+			name:    "airing",
+			args:    "[today|week] [season]",
+			summary: "List the episodes airing today or this week.",
+			help:    "Adding `season` leaves out shows that aren't from the current season.",
+			run:     airingCommand,
+		},
+		{
+			name:    "season",
+			args:    "[winter|spring|summer|fall] [year]",
+			summary: "Chart a season's anime, most popular first.",
+			help:    "Defaults to the current season.",
+			run:     seasonCommand,
+		},
+		{
+			name:    "follow",
+			args:    "<title|id>",
+			minArgs: 1,
+			summary: "Post in this channel whenever a new episode of an anime airs.",
+			run: func(c *commandContext) error {
+				return followCommand(c.s, c.channel, strings.Join(c.args, " "), c.settings)
+			},
+		},
+		{
+			name:    "unfollow",
+			args:    "<title|id>",
+			minArgs: 1,
+			summary: "Stop following an anime in this channel.",
+			run: func(c *commandContext) error {
+				return unfollowCommand(c.s, c.channel, strings.Join(c.args, " "), c.settings)
+			},
+		},
+		{
+			name:    "following",
+			summary: "List what this channel follows.",
+			run: func(c *commandContext) error {
+				return followingCommand(c.s, c.channel, c.settings.prefix())
+			},
+		},
+		{
+			name:    "config",
+			args:    "[setting] [value]",
+			summary: "Show or change this server's settings. Needs the Manage Server permission.",
+			help: "Settings are " + strings.Join(configOptionNames(), ", ") + ". " +
+				"`{prefix} config <setting>` explains a setting, and `{prefix} config reset` puts everything back to the defaults.",
+			run: configCommand,
+		},
+	}
+
+	commandsByName = make(map[string]*command)
+	for _, cmd := range commands {
+		for _, name := range append([]string{cmd.name}, cmd.aliases...) {
+			if _, ok := commandsByName[name]; ok {
+				panic(fmt.Sprintf("command name %q is used twice", name))
+			}
+			commandsByName[name] = cmd
+		}
+	}
+}
+
+// botCommand runs the command in request, the part of a message after the prefix. Mistakes and
+// failed lookups are answered in channel, so only errors nobody has been told about are returned.
+func botCommand(s *discordgo.Session, guild string, channel string, author string, request string) error {
+	r := csv.NewReader(strings.NewReader(strings.TrimSpace(request)))
+	r.Comma = ' ' // space
+	r.LazyQuotes = true
+	fields, err := r.Read()
+	if err != nil {
+		// Only an empty request has nothing to read.
+		fields = []string{"help"}
+	}
+
+	c := &commandContext{s: s, guild: guild, channel: channel, author: author, settings: settings.get(guild)}
+	prefix := c.settings.prefix()
+	name := strings.ToLower(fields[0])
+	cmd, ok := commandsByName[name]
+	if !ok {
+		return c.reply("I don't know the command `%s`. Try `%s help` to see what I can do.", fields[0], prefix)
+	}
+
+	c.raw = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(request), fields[0]))
+	for _, arg := range fields[1:] {
+		// Repeated spaces show up as empty fields.
+		if arg != "" {
+			c.args = append(c.args, arg)
+		}
+	}
+	if cmd.mediaType && len(c.args) > 0 {
+		switch strings.ToLower(c.args[0]) {
+		case "anime":
+			c.mediaType = anilist.MediaTypeAnime
+			c.args = c.args[1:]
+		case "manga":
+			c.mediaType = anilist.MediaTypeManga
+			c.args = c.args[1:]
+		default:
+			c.mediaType = c.settings.MediaType
+		}
+	}
+	if len(c.args) < cmd.minArgs {
+		return c.reply("Usage: %s", cmd.usage(prefix))
+	}
+
+	err = cmd.run(c)
+	var invalid usageError
+	var notFound notFoundError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &invalid):
+		return c.reply("Sorry, %s.\nUsage: %s", invalid.reason, cmd.usage(prefix))
+	case errors.As(err, &notFound):
+		return c.reply("Sorry, I couldn't find anything for \"%s\".", notFound.query)
+	}
+
+	fmt.Println(err)
+	reply := userMessage(err)
+	if reply == "" {
+		reply = fmt.Sprintf("Sorry, something went wrong running `%s`.", cmd.name)
+	}
+	return c.reply("%s", reply)
+}
+
+func helpCommand(c *commandContext) error {
+	if len(c.args) > 0 {
+		cmd, ok := commandsByName[strings.ToLower(c.args[0])]
+		if !ok {
+			return usageError{fmt.Sprintf("there's no command called `%s`", c.args[0])}
+		}
+
+		description := cmd.summary
+		if cmd.help != "" {
+			description += "\n\n" + strings.Replace(cmd.help, "{prefix}", c.settings.prefix(), -1)
+		}
+		if len(cmd.aliases) > 0 {
+			description += "\n\nAlso works as: " + strings.Join(cmd.aliases, ", ")
+		}
+		_, err := c.s.ChannelMessageSendEmbed(c.channel, &discordgo.MessageEmbed{
+			Title:       cmd.usage(c.settings.prefix()),
+			Description: description,
+			Color:       0x00ff00,
+		})
+		return err
+	}
+
+	lines := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		lines = append(lines, fmt.Sprintf("%s\n%s", cmd.usage(c.settings.prefix()), cmd.summary))
+	}
+	_, err := c.s.ChannelMessageSendEmbed(c.channel, &discordgo.MessageEmbed{
+		Title:       "Commands",
+		Description: truncate(strings.Join(lines, "\n\n"), maxDescriptionLength),
+		Color:       0x00ff00,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s help <command> explains a command in more detail", c.settings.prefix())},
+	})
+	return err
+}
+
+func titleCommand(c *commandContext) error {
+	for _, title := range c.args {
+		query := anilist.MediaQuery{Title: title, Type: c.mediaType, IsAdult: c.settings.adultFilter()}
+		if err := sendTitleMatch(context.Background(), c.s, Channel(c.channel), c.author, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func castCommand(c *commandContext) error {
+	ctx := context.Background()
+	for _, title := range c.args {
+		matches, err := ani.MatchTitle(ctx, anilist.MediaQuery{Title: title, Type: c.mediaType, IsAdult: c.settings.adultFilter()})
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return notFoundError{title}
+		}
+		if err := SendCast(c.s, Channel(c.channel), matches[0].Media.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func idCommand(c *commandContext) error {
+	ctx := context.Background()
+	for _, arg := range c.args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return usageError{fmt.Sprintf("\"%s\" isn't an AniList ID", arg)}
+		}
+		media, err := ani.MediaFromMediaID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := Send(c.s, Channel(c.channel), media); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func personCommand(c *commandContext) error {
+	ctx := context.Background()
+	for _, name := range c.args {
+		staff, err := ani.StaffFromPersonQuery(ctx, anilist.PersonQuery{Name: name, Type: c.mediaType, PerPage: 1})
+		if err != nil {
+			return err
+		}
+		if len(staff) == 0 {
+			return notFoundError{name}
+		}
+		if err := SendStaff(c.s, Channel(c.channel), staff[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func studioCommand(c *commandContext) error {
+	ctx := context.Background()
+	for _, name := range c.args {
+		studio, err := ani.StudioDetailFromStudioQuery(ctx, anilist.StudioQuery{Name: name, PerPage: productionsPerPage})
+		if err != nil {
+			return err
+		}
+		if studio.ID == 0 {
+			return notFoundError{name}
+		}
+		if err := SendStudio(c.s, Channel(c.channel), studio); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func characterCommand(c *commandContext) error {
+	ctx := context.Background()
+	for _, name := range c.args {
+		characters, err := ani.CharactersFromName(ctx, name, 1)
+		if err != nil {
+			return err
+		}
+		if len(characters) == 0 {
+			return notFoundError{name}
+		}
+		if err := SendCharacter(c.s, Channel(c.channel), characters[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func airingCommand(c *commandContext) error {
+	days := 1
+	seasonOnly := false
+	for _, arg := range c.args {
+		switch strings.ToLower(arg) {
+		case "today":
+			days = 1
+		case "week":
+			days = 7
+		case "season":
+			seasonOnly = true
+		default:
+			return usageError{fmt.Sprintf("\"%s\" isn't an airing option", arg)}
+		}
+	}
+	// Starting on the hour lets everyone asking within the same hour share cached results.
+	from := time.Now().Truncate(time.Hour)
+	return SendPage(c.s, Channel(c.channel), "airing", airingArg(from, days, seasonOnly), 1)
+}
+
+func seasonCommand(c *commandContext) error {
+	season, year := anilist.Season(time.Now())
+	for _, arg := range c.args {
+		if y, err := strconv.Atoi(arg); err == nil {
+			year = y
+			continue
+		}
+		switch s := anilist.MediaSeason(strings.ToUpper(arg)); {
+		case s.IsValid():
+			season = s
+		case s == "AUTUMN":
+			season = anilist.MediaSeasonFall
+		default:
+			return usageError{fmt.Sprintf("\"%s\" isn't a season", arg)}
+		}
+	}
+	return SendPage(c.s, Channel(c.channel), "season", seasonArg(season, year), 1)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestHelpUsesGuildPrefix makes sure no help text hard-codes the default prefix, which a guild may have changed.
+func TestHelpUsesGuildPrefix(t *testing.T) {
+	for _, cmd := range commands {
+		if strings.Contains(cmd.summary, commandPrefix) || strings.Contains(cmd.help, commandPrefix) {
+			t.Errorf("%s's help mentions %s instead of {prefix}", cmd.name, commandPrefix)
+		}
+	}
+}
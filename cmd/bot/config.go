@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+// guildSettings are what a server has configured with !anibot config. The zero value is the default for every setting.
+type guildSettings struct {
+	// Prefix replaces commandPrefix if set.
+	Prefix string `json:"prefix,omitempty"`
+	// DisabledInline lists the inlineSyntaxes the bot should ignore.
+	DisabledInline []string `json:"disabled_inline,omitempty"`
+	// Delimiters overrides the defaultDelimiters for some inlineSyntaxes.
+	Delimiters map[string]delimiters `json:"delimiters,omitempty"`
+	// Adult allows adult media to be posted.
+	Adult bool `json:"adult,omitempty"`
+	// MediaType is what to look for by default when a command takes either anime or manga, or "" for both.
+	MediaType anilist.MediaType `json:"media_type,omitempty"`
+	// Compact shortens media embeds.
+	Compact bool `json:"compact,omitempty"`
+	// HiddenStaff lists the anilist.RoleGroups whose key staff are left off media embeds.
+	HiddenStaff []anilist.RoleGroup `json:"hidden_staff,omitempty"`
+}
+
+func (settings guildSettings) prefix() string {
+	if settings.Prefix == "" {
+		return commandPrefix
+	}
+	return settings.Prefix
+}
+
+func (settings guildSettings) inline(syntax string) bool {
+	for _, disabled := range settings.DisabledInline {
+		if disabled == syntax {
+			return false
+		}
+	}
+	return true
+}
+
+func (settings guildSettings) delimiters(syntax string) delimiters {
+	if d, ok := settings.Delimiters[syntax]; ok {
+		return d
+	}
+	return defaultDelimiters[syntax]
+}
+
+// syntaxes maps each enabled inline syntax to its delimiters.
+func (settings guildSettings) syntaxes() map[string]delimiters {
+	syntaxes := make(map[string]delimiters)
+	for _, syntax := range inlineSyntaxes {
+		if settings.inline(syntax) {
+			syntaxes[syntax] = settings.delimiters(syntax)
+		}
+	}
+	return syntaxes
+}
+
+// staffGroups are the anilist.RoleGroups whose key staff are listed on media embeds.
+func (settings guildSettings) staffGroups() []anilist.RoleGroup {
+	var groups []anilist.RoleGroup
+	for _, group := range anilist.RoleGroups {
+		hidden := false
+		for _, h := range settings.HiddenStaff {
+			if h == group {
+				hidden = true
+			}
+		}
+		if !hidden {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// staffGroupName is what a RoleGroup is called in !anibot config, e.g. "original" for Original Work.
+func staffGroupName(group anilist.RoleGroup) string {
+	return strings.ToLower(strings.Fields(string(group))[0])
+}
+
+func staffGroupNames() []string {
+	names := make([]string, 0, len(anilist.RoleGroups))
+	for _, group := range anilist.RoleGroups {
+		names = append(names, staffGroupName(group))
+	}
+	return names
+}
+
+// adultFilter is the anilist.MediaQuery IsAdult filter that keeps out adult media where it isn't allowed.
+func (settings guildSettings) adultFilter() *bool {
+	if settings.Adult {
+		return nil
+	}
+	notAdult := false
+	return &notAdult
+}
+
+// errAdult is returned when asked to post adult media somewhere that doesn't allow it.
+var errAdult = errors.New("adult media isn't allowed here")
+
+// settingsStore holds every guild's settings. If it has a path, it is written there on every change.
+type settingsStore struct {
+	mu   sync.Mutex
+	path string
+
+	Guilds map[string]guildSettings `json:"guilds"`
+}
+
+var settings = settingsStore{Guilds: make(map[string]guildSettings)}
+
+// get returns the settings for guild. Direct messages have no guild, and always use the defaults.
+func (st *settingsStore) get(guild string) guildSettings {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.Guilds[guild]
+}
+
+func (st *settingsStore) set(guild string, guildSettings guildSettings) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.Guilds[guild] = guildSettings
+	st.save()
+}
+
+// load restores the settings saved at path, and keeps saving changes there from now on.
+// A missing file is not an error, it just means there is nothing to restore.
+func (st *settingsStore) load(path string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.path = path
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, st); err != nil {
+		return err
+	}
+	if st.Guilds == nil {
+		st.Guilds = make(map[string]guildSettings)
+	}
+	return nil
+}
+
+// save writes the settings to their path. The caller must hold st.mu.
+func (st *settingsStore) save() {
+	if st.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		fmt.Println("Error saving settings: ", err)
+		return
+	}
+
+	// Write to a temporary file first so a crash mid-write doesn't lose every server's settings.
+	tmp := st.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		fmt.Println("Error saving settings: ", err)
+		return
+	}
+	if err := os.Rename(tmp, st.path); err != nil {
+		fmt.Println("Error saving settings: ", err)
+	}
+}
+
+type settingsKey struct{}
+
+// withSettings makes a guild's settings available to page renderers further down the line.
+func withSettings(ctx context.Context, guildSettings guildSettings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, guildSettings)
+}
+
+// settingsFrom returns the settings stored in ctx by withSettings, or the defaults if there are none.
+func settingsFrom(ctx context.Context) guildSettings {
+	guildSettings, _ := ctx.Value(settingsKey{}).(guildSettings)
+	return guildSettings
+}
+
+// channelGuild is the guild a channel belongs to, or "" for direct messages.
+func channelGuild(s *discordgo.Session, channel string) string {
+	if c, err := s.State.Channel(channel); err == nil {
+		return c.GuildID
+	}
+	if c, err := s.Channel(channel); err == nil {
+		return c.GuildID
+	}
+	return ""
+}
+
+// configOption is a setting that can be changed with !anibot config.
+type configOption struct {
+	usage string
+	show  func(settings guildSettings) string
+	set   func(settings *guildSettings, value string) error
+}
+
+var configOptions = map[string]configOption{
+	"prefix": {
+		usage: "prefix <prefix>",
+		show:  func(settings guildSettings) string { return "`" + settings.prefix() + "`" },
+		set: func(settings *guildSettings, value string) error {
+			if value == "" || strings.ContainsAny(value, " \t\n`") || len(value) > 16 {
+				return errors.New("the prefix has to be up to 16 characters, without spaces or backticks")
+			}
+			settings.Prefix = value
+			if value == commandPrefix {
+				settings.Prefix = ""
+			}
+			return nil
+		},
+	},
+	"inline": {
+		usage: "inline <all|none|" + strings.Join(inlineSyntaxes, ",") + ">",
+		show: func(settings guildSettings) string {
+			var enabled []string
+			for _, syntax := range inlineSyntaxes {
+				if settings.inline(syntax) {
+					enabled = append(enabled, syntax)
+				}
+			}
+			if len(enabled) == 0 {
+				return "none"
+			}
+			return strings.Join(enabled, ", ")
+		},
+		set: func(settings *guildSettings, value string) error {
+			enabled := make(map[string]bool)
+			switch strings.ToLower(value) {
+			case "all":
+				for _, syntax := range inlineSyntaxes {
+					enabled[syntax] = true
+				}
+			case "none":
+			default:
+				for _, syntax := range strings.Split(strings.ToLower(value), ",") {
+					syntax = strings.TrimSpace(syntax)
+					if !contains(inlineSyntaxes, syntax) {
+						return fmt.Errorf("\"%s\" isn't an inline syntax, try %s", syntax, strings.Join(inlineSyntaxes, ", "))
+					}
+					enabled[syntax] = true
+				}
+			}
+
+			settings.DisabledInline = nil
+			for _, syntax := range inlineSyntaxes {
+				if !enabled[syntax] {
+					settings.DisabledInline = append(settings.DisabledInline, syntax)
+				}
+			}
+			return nil
+		},
+	},
+	"delimiters": {
+		usage: "delimiters <" + strings.Join(inlineSyntaxes, "|") + "> <open> <close|default>",
+		show: func(settings guildSettings) string {
+			shown := make([]string, 0, len(inlineSyntaxes))
+			for _, syntax := range inlineSyntaxes {
+				shown = append(shown, fmt.Sprintf("%s `%s`", syntax, settings.delimiters(syntax)))
+			}
+			return strings.Join(shown, ", ")
+		},
+		set: func(settings *guildSettings, value string) error {
+			fields := strings.Fields(value)
+			if len(fields) < 2 || !contains(inlineSyntaxes, strings.ToLower(fields[0])) {
+				return fmt.Errorf("say which kind of request (%s) and its delimiters, e.g. `anime {{ }}`", strings.Join(inlineSyntaxes, ", "))
+			}
+			syntax := strings.ToLower(fields[0])
+
+			// Copy the map so we don't change the stored settings before they're saved.
+			custom := make(map[string]delimiters)
+			for k, v := range settings.Delimiters {
+				custom[k] = v
+			}
+			if len(fields) == 2 && strings.ToLower(fields[1]) == "default" {
+				delete(custom, syntax)
+			} else {
+				if len(fields) != 3 {
+					return errors.New("give both an opening and closing delimiter, separated by a space")
+				}
+				d := delimiters{Open: fields[1], Close: fields[2]}
+				for _, delimiter := range []string{d.Open, d.Close} {
+					if len(delimiter) > maxDelimiterLength || delimiter == "" || strings.ContainsAny(delimiter, "\\`") {
+						return fmt.Errorf("delimiters have to be up to %d characters, without \\ or `", maxDelimiterLength)
+					}
+				}
+				for _, other := range inlineSyntaxes {
+					if other != syntax && settings.delimiters(other).Open == d.Open {
+						return fmt.Errorf("%s requests already start with `%s`", other, d.Open)
+					}
+				}
+				custom[syntax] = d
+			}
+
+			settings.Delimiters = custom
+			if len(custom) == 0 {
+				settings.Delimiters = nil
+			}
+			return nil
+		},
+	},
+	"staff": {
+		usage: "staff <all|none|" + strings.Join(staffGroupNames(), ",") + ">",
+		show: func(settings guildSettings) string {
+			var shown []string
+			for _, group := range settings.staffGroups() {
+				shown = append(shown, staffGroupName(group))
+			}
+			if len(shown) == 0 {
+				return "none"
+			}
+			return strings.Join(shown, ", ")
+		},
+		set: func(settings *guildSettings, value string) error {
+			shown := make(map[string]bool)
+			switch strings.ToLower(value) {
+			case "all":
+				for _, name := range staffGroupNames() {
+					shown[name] = true
+				}
+			case "none":
+			default:
+				for _, name := range strings.Split(strings.ToLower(value), ",") {
+					name = strings.TrimSpace(name)
+					if !contains(staffGroupNames(), name) {
+						return fmt.Errorf("\"%s\" isn't a group of staff, try %s", name, strings.Join(staffGroupNames(), ", "))
+					}
+					shown[name] = true
+				}
+			}
+
+			settings.HiddenStaff = nil
+			for _, group := range anilist.RoleGroups {
+				if !shown[staffGroupName(group)] {
+					settings.HiddenStaff = append(settings.HiddenStaff, group)
+				}
+			}
+			return nil
+		},
+	},
+	"adult": {
+		usage: "adult <on|off>",
+		show:  func(settings guildSettings) string { return onOff(settings.Adult) },
+		set: func(settings *guildSettings, value string) (err error) {
+			settings.Adult, err = parseOnOff(value)
+			return
+		},
+	},
+	"type": {
+		usage: "type <anime|manga|any>",
+		show: func(settings guildSettings) string {
+			if settings.MediaType == "" {
+				return "any"
+			}
+			return strings.ToLower(settings.MediaType.String())
+		},
+		set: func(settings *guildSettings, value string) error {
+			switch strings.ToLower(value) {
+			case "anime", "manga":
+				settings.MediaType = anilist.MediaType(strings.ToUpper(value))
+			case "any":
+				settings.MediaType = ""
+			default:
+				return fmt.Errorf("the type has to be anime, manga or any, not \"%s\"", value)
+			}
+			return nil
+		},
+	},
+	"verbosity": {
+		usage: "verbosity <full|compact>",
+		show: func(settings guildSettings) string {
+			if settings.Compact {
+				return "compact"
+			}
+			return "full"
+		},
+		set: func(settings *guildSettings, value string) error {
+			switch strings.ToLower(value) {
+			case "full":
+				settings.Compact = false
+			case "compact":
+				settings.Compact = true
+			default:
+				return fmt.Errorf("verbosity has to be full or compact, not \"%s\"", value)
+			}
+			return nil
+		},
+	},
+}
+
+func configOptionNames() []string {
+	names := make([]string, 0, len(configOptions))
+	for name := range configOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configCommand shows or changes the settings of the guild it's used in. Only members who can
+// manage the server are allowed to use it.
+func configCommand(c *commandContext) error {
+	if c.guild == "" {
+		return c.reply("Settings only apply to servers, so there's nothing to configure here.")
+	}
+	permissions, err := c.s.UserChannelPermissions(c.author, c.channel)
+	if err != nil {
+		return err
+	}
+	if permissions&discordgo.PermissionManageServer == 0 {
+		return c.reply("Sorry, only members with the Manage Server permission can change my settings.")
+	}
+
+	current := settings.get(c.guild)
+	if len(c.args) == 0 {
+		lines := make([]string, 0, len(configOptions))
+		for _, name := range configOptionNames() {
+			lines = append(lines, fmt.Sprintf("**%s**: %s", name, configOptions[name].show(current)))
+		}
+		_, err := c.s.ChannelMessageSendEmbed(c.channel, &discordgo.MessageEmbed{
+			Title:       "Settings",
+			Description: strings.Join(lines, "\n"),
+			Color:       0x00ff00,
+			Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s config <setting> <value> changes a setting", current.prefix())},
+		})
+		return err
+	}
+
+	name := strings.ToLower(c.args[0])
+	if name == "reset" {
+		settings.set(c.guild, guildSettings{})
+		return c.reply("All settings are back to their defaults. The prefix is `%s` again.", commandPrefix)
+	}
+	option, ok := configOptions[name]
+	if !ok {
+		return usageError{fmt.Sprintf("there's no setting called \"%s\", try one of %s", c.args[0], strings.Join(configOptionNames(), ", "))}
+	}
+	if len(c.args) < 2 {
+		return c.reply("**%s** is %s. Change it with `%s config %s`.", name, option.show(current), current.prefix(), option.usage)
+	}
+
+	if err := option.set(&current, strings.Join(c.args[1:], " ")); err != nil {
+		return usageError{err.Error()}
+	}
+	settings.set(c.guild, current)
+	return c.reply("**%s** is now %s.", name, option.show(current))
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func parseOnOff(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "yes", "true", "allow":
+		return true, nil
+	case "off", "no", "false", "deny":
+		return false, nil
+	}
+	return false, fmt.Errorf("that has to be on or off, not \"%s\"", value)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	pollInterval = 5 * time.Minute
+	// If the bot has been down for a while, don't flood channels with everything they missed.
+	maxCatchUp = 24 * time.Hour
+)
+
+// subscriptionStore tracks which media each channel follows. If it has a path, it is written there
+// on every change so subscriptions outlive a restart.
+type subscriptionStore struct {
+	mu   sync.Mutex
+	path string
+
+	// Channels maps channel ID to the followed media IDs, and their titles for listing.
+	Channels map[string]map[int]string `json:"channels"`
+	// LastChecked is the end of the last window of airing episodes we posted about.
+	LastChecked time.Time `json:"last_checked"`
+}
+
+var subscriptions = subscriptionStore{Channels: make(map[string]map[int]string)}
+
+// follow subscribes channel to media, returning false if it was already following it.
+func (st *subscriptionStore) follow(channel string, media anilist.Media) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	followed, ok := st.Channels[channel]
+	if !ok {
+		followed = make(map[int]string)
+		st.Channels[channel] = followed
+	}
+	if _, ok := followed[media.ID]; ok {
+		return false
+	}
+	followed[media.ID] = media.Title.Romaji
+	st.save()
+	return true
+}
+
+// unfollow unsubscribes channel from the media with id, returning false if it wasn't following it.
+func (st *subscriptionStore) unfollow(channel string, id int) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	followed := st.Channels[channel]
+	if _, ok := followed[id]; !ok {
+		return false
+	}
+	delete(followed, id)
+	if len(followed) == 0 {
+		delete(st.Channels, channel)
+	}
+	st.save()
+	return true
+}
+
+// following lists the titles of the media channel follows, alphabetically.
+func (st *subscriptionStore) following(channel string) []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var titles []string
+	for _, title := range st.Channels[channel] {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	return titles
+}
+
+// find looks for a followed media in channel by case-insensitive title.
+func (st *subscriptionStore) find(channel string, title string) (int, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for id, followed := range st.Channels[channel] {
+		if strings.EqualFold(followed, title) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// followers maps each followed media ID to the channels following it.
+func (st *subscriptionStore) followers() map[int][]string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	followers := make(map[int][]string)
+	for channel, followed := range st.Channels {
+		for id := range followed {
+			followers[id] = append(followers[id], channel)
+		}
+	}
+	return followers
+}
+
+func (st *subscriptionStore) lastChecked() time.Time {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.LastChecked
+}
+
+func (st *subscriptionStore) checked(until time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.LastChecked = until
+	st.save()
+}
+
+// load restores the subscriptions saved at path, and keeps saving changes there from now on.
+// A missing file is not an error, it just means there is nothing to restore.
+func (st *subscriptionStore) load(path string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.path = path
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, st); err != nil {
+		return err
+	}
+	if st.Channels == nil {
+		st.Channels = make(map[string]map[int]string)
+	}
+	return nil
+}
+
+// save writes the subscriptions to their path. The caller must hold st.mu.
+func (st *subscriptionStore) save() {
+	if st.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		fmt.Println("Error saving subscriptions: ", err)
+		return
+	}
+
+	// Write to a temporary file first so a crash mid-write doesn't lose every subscription.
+	tmp := st.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		fmt.Println("Error saving subscriptions: ", err)
+		return
+	}
+	if err := os.Rename(tmp, st.path); err != nil {
+		fmt.Println("Error saving subscriptions: ", err)
+	}
+}
+
+// watchAiring posts to following channels whenever a new episode of something they follow airs.
+func watchAiring(s *discordgo.Session) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		checkAiring(s, time.Now())
+	}
+}
+
+func checkAiring(s *discordgo.Session, now time.Time) {
+	from := subscriptions.lastChecked()
+	if from.IsZero() {
+		from = now.Add(-pollInterval)
+	}
+	if now.Sub(from) > maxCatchUp {
+		from = now.Add(-maxCatchUp)
+	}
+
+	followers := subscriptions.followers()
+	if len(followers) == 0 {
+		subscriptions.checked(now)
+		return
+	}
+	ids := make([]int, 0, len(followers))
+	for id := range followers {
+		ids = append(ids, id)
+	}
+
+	// The schedule can shift right up until an episode airs, so don't trust the cache here.
+	// AiringQuery bounds are exclusive, so nudge To forward a second to include episodes airing right now.
+	ctx := anilist.WithoutCache(context.Background())
+	schedules, err := ani.AiringSchedulesFromAiringQuery(ctx, anilist.AiringQuery{
+		From:     from,
+		To:       now.Add(time.Second),
+		MediaIDs: ids,
+	})
+	if err != nil {
+		// Leave LastChecked alone so the next poll covers this window again.
+		fmt.Println("Error checking airing schedules: ", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		media, err := ani.MediaFromMediaID(ctx, schedule.Media.ID)
+		if err != nil {
+			fmt.Println("Error getting Media", err)
+			continue
+		}
+		for _, channel := range followers[media.ID] {
+			if err := SendEpisode(s, Channel(channel), schedule, media); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+	subscriptions.checked(now)
+}
+
+// unsureError is returned by resolveMedia when no title matches closely enough to follow without asking.
+type unsureError struct {
+	request string
+	// candidates are the likeliest matches, best first.
+	candidates []anilist.Media
+}
+
+func (e unsureError) Error() string {
+	return fmt.Sprintf("no close match for %q", e.request)
+}
+
+// maxFollowCandidates is how many candidates are suggested when a follow request is ambiguous.
+const maxFollowCandidates = 5
+
+// resolveMedia finds the anime a follow/unfollow command refers to, by AniList ID or title, leaving out
+// adult anime if guildSettings doesn't allow them. Titles are matched like any other lookup, and when none
+// match closely enough, the likeliest candidates are returned in an unsureError.
+func resolveMedia(ctx context.Context, request string, guildSettings guildSettings) (anilist.Media, error) {
+	if id, err := strconv.Atoi(request); err == nil {
+		media, err := ani.MediaFromMediaID(ctx, id)
+		if err != nil {
+			return anilist.Media{}, err
+		}
+		// An ID can be any media, so it has to be checked for what the title search filters out.
+		if media.Type != anilist.MediaTypeAnime {
+			return anilist.Media{}, errNoResults
+		}
+		if media.IsAdult && !guildSettings.Adult {
+			return anilist.Media{}, errAdult
+		}
+		return media, nil
+	}
+
+	matches, err := ani.MatchTitle(ctx, anilist.MediaQuery{Title: request, Type: anilist.MediaTypeAnime, IsAdult: guildSettings.adultFilter()})
+	if err != nil {
+		return anilist.Media{}, err
+	}
+	if len(matches) == 0 {
+		return anilist.Media{}, errNoResults
+	}
+	if matches[0].Confidence < confidentMatch {
+		unsure := unsureError{request: request}
+		for _, match := range matches {
+			if len(unsure.candidates) == maxFollowCandidates {
+				break
+			}
+			unsure.candidates = append(unsure.candidates, match.Media)
+		}
+		return anilist.Media{}, unsure
+	}
+	return matches[0].Media, nil
+}
+
+func followCommand(s *discordgo.Session, channel string, request string, guildSettings guildSettings) error {
+	media, err := resolveMedia(context.Background(), request, guildSettings)
+	var unsure unsureError
+	if err == errNoResults {
+		_, err = s.ChannelMessageSend(channel, fmt.Sprintf("Sorry, I couldn't find an anime called \"%s\".", request))
+		return err
+	} else if errors.As(err, &unsure) {
+		lines := make([]string, 0, len(unsure.candidates))
+		for _, candidate := range unsure.candidates {
+			lines = append(lines, fmt.Sprintf("**%s**: `%s follow %d`", candidate.Title.Romaji, guildSettings.prefix(), candidate.ID))
+		}
+		reply := fmt.Sprintf("I'm not sure which anime you mean by \"%s\". Did you mean one of these?\n%s", request, strings.Join(lines, "\n"))
+		_, err = s.ChannelMessageSend(channel, reply)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	var reply string
+	if !subscriptions.follow(channel, media) {
+		reply = fmt.Sprintf("This channel is already following **%s**.", media.Title.Romaji)
+	} else if media.Status != anilist.MediaStatusReleasing && media.Status != anilist.MediaStatusNotYetReleased {
+		reply = fmt.Sprintf("Following **%s**, but it isn't airing right now, so don't expect to hear about it soon.", media.Title.Romaji)
+	} else {
+		reply = fmt.Sprintf("Following **%s**. I'll post here when new episodes air.", media.Title.Romaji)
+	}
+	_, err = s.ChannelMessageSend(channel, reply)
+	return err
+}
+
+func unfollowCommand(s *discordgo.Session, channel string, request string, guildSettings guildSettings) error {
+	// Prefer what the channel is actually following over whatever a fresh search turns up.
+	id, ok := subscriptions.find(channel, request)
+	if !ok {
+		media, err := resolveMedia(context.Background(), request, guildSettings)
+		var unsure unsureError
+		if err != nil && err != errNoResults && !errors.As(err, &unsure) {
+			return err
+		}
+		id = media.ID
+	}
+
+	reply := fmt.Sprintf("This channel isn't following \"%s\".", request)
+	if subscriptions.unfollow(channel, id) {
+		reply = fmt.Sprintf("Unfollowed \"%s\".", request)
+	}
+	_, err := s.ChannelMessageSend(channel, reply)
+	return err
+}
+
+func followingCommand(s *discordgo.Session, channel string, prefix string) error {
+	titles := subscriptions.following(channel)
+	reply := fmt.Sprintf("This channel isn't following anything. Use `%s follow <title>` to start.", prefix)
+	if len(titles) > 0 {
+		reply = "This channel is following:\n" + strings.Join(titles, "\n")
+	}
+	_, err := s.ChannelMessageSend(channel, reply)
+	return err
+}
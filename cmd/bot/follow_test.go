@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+)
+
+// withMedia points ani at a stand-in for AniList that answers every lookup by ID with media.
+func withMedia(t *testing.T, media string) {
+	withData(t, fmt.Sprintf(`{"Media":%s}`, media))
+}
+
+// withData points ani at a stand-in for AniList that answers every request with data.
+func withData(t *testing.T, data string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":%s}`, data)
+	}))
+	previous := ani
+	ani = anilist.NewClient(anilist.WithEndpoint(srv.URL))
+	t.Cleanup(func() {
+		ani = previous
+		srv.Close()
+	})
+}
+
+func TestResolveMediaByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		media    string
+		settings guildSettings
+		want     error
+	}{
+		{"anime", `{"id":1,"type":"ANIME","isAdult":false}`, guildSettings{}, nil},
+		{"manga", `{"id":1,"type":"MANGA","isAdult":false}`, guildSettings{}, errNoResults},
+		{"adult", `{"id":1,"type":"ANIME","isAdult":true}`, guildSettings{}, errAdult},
+		{"adult allowed", `{"id":1,"type":"ANIME","isAdult":true}`, guildSettings{Adult: true}, nil},
+		{"adult manga", `{"id":1,"type":"MANGA","isAdult":true}`, guildSettings{Adult: true}, errNoResults},
+	}
+	for _, test := range tests {
+		withMedia(t, test.media)
+		media, err := resolveMedia(context.Background(), "1", test.settings)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+		if err == nil && media.ID != 1 {
+			t.Errorf("%s: got media %d", test.name, media.ID)
+		}
+	}
+}
+
+func TestResolveMediaByTitle(t *testing.T) {
+	page := func(medias string) string {
+		return fmt.Sprintf(`{"Page":{"pageInfo":{"currentPage":1,"lastPage":1},"media":[%s]}}`, medias)
+	}
+	const (
+		bebop  = `{"id":1,"type":"ANIME","popularity":300000,"title":{"romaji":"Cowboy Bebop"}}`
+		movie  = `{"id":5,"type":"ANIME","popularity":80000,"title":{"romaji":"Cowboy Bebop: Tengoku no Tobira"}}`
+		trigun = `{"id":6,"type":"ANIME","popularity":100000,"title":{"romaji":"Trigun"}}`
+	)
+
+	withData(t, page(movie+","+bebop))
+	media, err := resolveMedia(context.Background(), "cowboy bebop", guildSettings{})
+	if err != nil || media.ID != 1 {
+		t.Errorf("expected the exact title to be followed, got %d, %v", media.ID, err)
+	}
+
+	withData(t, page(bebop+","+movie+","+trigun))
+	_, err = resolveMedia(context.Background(), "bebop", guildSettings{})
+	var unsure unsureError
+	if !errors.As(err, &unsure) {
+		t.Fatalf("expected a partial title to be unsure, got %v", err)
+	}
+	if len(unsure.candidates) != 3 || unsure.candidates[0].ID != 1 {
+		t.Errorf("expected the candidates best first, got %v", unsure.candidates)
+	}
+
+	withData(t, page(""))
+	if _, err := resolveMedia(context.Background(), "nothing", guildSettings{}); err != errNoResults {
+		t.Errorf("expected errNoResults, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+// delimiters surround an inline request in a message, like the braces in {Cowboy Bebop}.
+type delimiters struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+func (d delimiters) String() string {
+	return d.Open + "…" + d.Close
+}
+
+// inlineSyntaxes are the kinds of inline request, in the order they're listed to users.
+var inlineSyntaxes = []string{"anime", "manga", "character", "staff"}
+
+var defaultDelimiters = map[string]delimiters{
+	"anime":     {Open: "{", Close: "}"},
+	"manga":     {Open: "<", Close: ">"},
+	"character": {Open: "[[", Close: "]]"},
+	"staff":     {Open: "((", Close: "))"},
+}
+
+const maxDelimiterLength = 4
+
+var (
+	// Code is left alone, since it's the likeliest place for braces and angle brackets to turn up.
+	codePattern = regexp.MustCompile("(?s)```.*?```|``.*?``|`[^`]*`")
+	// Discord formats mentions, custom emoji, channel links, timestamps and slash commands in angle brackets,
+	// and wrapping a URL in them stops it from being previewed.
+	markupPattern = regexp.MustCompile(`^<(?:@[!&]?\d+|#\d+|a?:\w+:\d+|t:-?\d+(?::[a-zA-Z])?|/[\w -]+:\d+|https?://[^\s>]+)>`)
+	urlPattern    = regexp.MustCompile(`^https?://\S+`)
+)
+
+// inlineRequest is a lookup asked for in the middle of a message.
+type inlineRequest struct {
+	// kind is one of inlineSyntaxes.
+	kind  string
+	query string
+}
+
+// parseInline finds the inline requests in content, using the delimiters for each enabled kind of request.
+// Code, Discord markup and URLs are skipped over, and a backslash stops the character after it from
+// starting or ending a request. See isRequest for what counts as one.
+func parseInline(content string, syntaxes map[string]delimiters) []inlineRequest {
+	// Try longer delimiters first, so [[ wins over [ if a guild has set up both.
+	kinds := make([]string, 0, len(syntaxes))
+	for kind := range syntaxes {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		return len(syntaxes[kinds[i]].Open) > len(syntaxes[kinds[j]].Open)
+	})
+
+	content = codePattern.ReplaceAllString(content, " ")
+
+	var requests []inlineRequest
+	for i := 0; i < len(content); {
+		rest := content[i:]
+		if rest[0] == '\\' {
+			i += 2
+			continue
+		}
+		if skip := markupPattern.FindString(rest); skip != "" {
+			i += len(skip)
+			continue
+		}
+		if skip := urlPattern.FindString(rest); skip != "" {
+			i += len(skip)
+			continue
+		}
+
+		moved := false
+		for _, kind := range kinds {
+			d := syntaxes[kind]
+			if !strings.HasPrefix(rest, d.Open) {
+				continue
+			}
+			// {{this}} is how templates are written, not a request, so a doubled delimiter is skipped
+			// along with the rest of its run.
+			last := d.Open[len(d.Open)-1]
+			if len(rest) > len(d.Open) && rest[len(d.Open)] == last {
+				i += len(d.Open)
+				for i < len(content) && content[i] == last {
+					i++
+				}
+				moved = true
+				break
+			}
+			end := findClose(rest[len(d.Open):], d.Close)
+			if end < 0 {
+				continue
+			}
+			inner := rest[len(d.Open) : len(d.Open)+end]
+			if !isRequest(inner, d) {
+				continue
+			}
+			requests = append(requests, inlineRequest{kind: kind, query: unescape(inner)})
+			i += len(d.Open) + end + len(d.Close)
+			moved = true
+			break
+		}
+		if !moved {
+			i++
+		}
+	}
+	return requests
+}
+
+// isRequest reports whether inner, the text between an open and close delimiter, is a request.
+// Like *emphasis* in Markdown, it has to hug its delimiters, so "a < b and c > d" isn't asking for a manga.
+// It can't contain another opening delimiter either: in {a {b} c}, only {b} is a request.
+func isRequest(inner string, d delimiters) bool {
+	if inner == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(inner)
+	last, _ := utf8.DecodeLastRuneInString(inner)
+	if unicode.IsSpace(first) || unicode.IsSpace(last) {
+		return false
+	}
+	return findClose(inner, d.Open) < 0
+}
+
+// findClose is the index in s of the first unescaped close, or -1 if the request isn't closed on the same line.
+func findClose(s string, close string) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '\n':
+			return -1
+		case strings.HasPrefix(s[i:], close):
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape drops the backslashes from escaped characters.
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// inlineLookup answers one inline request in channel, made by requester.
+func inlineLookup(s *discordgo.Session, channel string, requester string, guildSettings guildSettings, request inlineRequest) {
+	ctx := context.Background()
+
+	var requestType anilist.MediaType
+	switch request.kind {
+	case "anime":
+		requestType = anilist.MediaTypeAnime
+	case "manga":
+		requestType = anilist.MediaTypeManga
+	case "character":
+		characters, err := ani.CharactersFromName(ctx, request.query, 1)
+		if err != nil {
+			reportError(s, channel, err)
+			return
+		}
+		if len(characters) > 0 {
+			SendCharacter(s, Channel(channel), characters[0])
+		}
+		return
+	case "staff":
+		staff, err := ani.StaffFromPersonName(ctx, request.query, 1)
+		if err != nil {
+			reportError(s, channel, err)
+			return
+		}
+		if len(staff) > 0 {
+			SendStaff(s, Channel(channel), staff[0])
+		}
+		return
+	default:
+		fmt.Println("Unknown inline request: ", request.kind)
+		return
+	}
+
+	query := anilist.MediaQuery{Title: request.query, Type: requestType, IsAdult: guildSettings.adultFilter()}
+	err := sendTitleMatch(context.Background(), s, Channel(channel), requester, query)
+	// Inline requests are often just something in braces that wasn't meant for us, so stay quiet when nothing turns up.
+	var notFound notFoundError
+	if err != nil && !errors.As(err, &notFound) && !errors.Is(err, anilist.ErrNotFound) {
+		reportError(s, channel, err)
+	}
+}
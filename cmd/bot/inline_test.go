@@ -0,0 +1,126 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []inlineRequest
+	}{
+		{"Have you guys heard of {Hunter x Hunter}?", []inlineRequest{{"anime", "Hunter x Hunter"}}},
+		{"<Berserk> is way better", []inlineRequest{{"manga", "Berserk"}}},
+		{"[[Killua]] is the best", []inlineRequest{{"character", "Killua"}}},
+		{"((Shinichiro Watanabe)) directed it", []inlineRequest{{"staff", "Shinichiro Watanabe"}}},
+		{"{Cowboy Bebop} and <Pluto>", []inlineRequest{{"anime", "Cowboy Bebop"}, {"manga", "Pluto"}}},
+		{"{Re:Zero}", []inlineRequest{{"anime", "Re:Zero"}}},
+		{"no requests here", nil},
+		{"{}", nil},
+		{"{unclosed", nil},
+		{"{split\nacross lines}", nil},
+
+		// Code is left alone.
+		{"`{not this}` but {this}", []inlineRequest{{"anime", "this"}}},
+		{"``{not} `this` either``", nil},
+		{"```\nfunc main() { <-done }\n```", nil},
+		{"```go\nm := map[string]int{}\n``` then {Monster}", []inlineRequest{{"anime", "Monster"}}},
+
+		// So is Discord's markup.
+		{"<@123> have you read <Vagabond>?", []inlineRequest{{"manga", "Vagabond"}}},
+		{"<@!123> <@&456>", nil},
+		{"<:pog:1> <a:dance:2>", nil},
+		{"see <#1>", nil},
+		{"<t:1600000000> <t:1600000000:R> <t:-1:f>", nil},
+		{"</find anime:1>", nil},
+		{"<https://anilist.co/anime/1>", nil},
+		{"https://example.com/?q={x}", nil},
+
+		// Escapes.
+		{`\{not a request}`, nil},
+		{`{not a request\}`, nil},
+		{`{Fate\}stay night}`, []inlineRequest{{"anime", "Fate}stay night"}}},
+		{`\\{a request}`, []inlineRequest{{"anime", "a request"}}},
+
+		// A request has to hug its delimiters, so comparisons and spaced-out brackets aren't requests.
+		{"a < b and c > d", nil},
+		{"if a < b and <Berserk>", []inlineRequest{{"manga", "Berserk"}}},
+		{"{ Cowboy Bebop}", nil},
+		{"{Cowboy Bebop }", nil},
+		{"{ }", nil},
+		{"{\tMonster}", nil},
+
+		// Doubled delimiters are template syntax, not requests.
+		{"{{double}}", nil},
+		{"{{{triple}}}", nil},
+		{"[[[Killua]]]", nil},
+		{"{{template}} and {Monster}", []inlineRequest{{"anime", "Monster"}}},
+
+		// Only the innermost of nested delimiters counts.
+		{"{a {b} c}", []inlineRequest{{"anime", "b"}}},
+		{"{Cowboy <Bebop>}", []inlineRequest{{"anime", "Cowboy <Bebop>"}}},
+		{`{a \{b} c}`, []inlineRequest{{"anime", "a {b"}}},
+	}
+
+	for _, test := range tests {
+		got := parseInline(test.content, defaultDelimiters)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseInline(%q) = %v, want %v", test.content, got, test.want)
+		}
+	}
+}
+
+func TestParseInlineCustomDelimiters(t *testing.T) {
+	syntaxes := map[string]delimiters{
+		"anime":     {Open: "{{", Close: "}}"},
+		"manga":     {Open: "{", Close: "}"},
+		"character": {Open: "!(", Close: ")"},
+	}
+	tests := []struct {
+		content string
+		want    []inlineRequest
+	}{
+		// The longer delimiter is tried first.
+		{"{{Monster}}", []inlineRequest{{"anime", "Monster"}}},
+		{"{Monster}", []inlineRequest{{"manga", "Monster"}}},
+		{"{{{Monster}}}", nil},
+		{"!!(Spike)", []inlineRequest{{"character", "Spike"}}},
+	}
+
+	for _, test := range tests {
+		got := parseInline(test.content, syntaxes)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseInline(%q) = %v, want %v", test.content, got, test.want)
+		}
+	}
+}
+
+func TestParseInlineOnlyEnabledKinds(t *testing.T) {
+	got := parseInline("{Monster} <Monster>", map[string]delimiters{"manga": defaultDelimiters["manga"]})
+	want := []inlineRequest{{"manga", "Monster"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindClose(t *testing.T) {
+	tests := []struct {
+		s     string
+		close string
+		want  int
+	}{
+		{"abc}", "}", 3},
+		{"}", "}", 0},
+		{"abc", "}", -1},
+		{`a\}b}`, "}", 4},
+		{`a\}`, "}", -1},
+		{"a\n}", "}", -1},
+		{"a]b]]", "]]", 3},
+	}
+	for _, test := range tests {
+		if got := findClose(test.s, test.close); got != test.want {
+			t.Errorf("findClose(%q, %q) = %d, want %d", test.s, test.close, got, test.want)
+		}
+	}
+}
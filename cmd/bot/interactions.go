@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// Autocomplete choices carry the AniList ID of the selected entry, so picking one
+	// always resolves to exactly what was shown, rather than searching again.
+	idChoicePrefix = "id:"
+
+	maxChoices        = 25
+	maxChoiceNameSize = 100
+)
+
+var errNoResults = errors.New("no results")
+
+var slashCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "anime",
+		Description: "Look up an anime",
+		Options:     []*discordgo.ApplicationCommandOption{searchOption("title", "Title of the anime")},
+	},
+	{
+		Name:        "manga",
+		Description: "Look up a manga",
+		Options:     []*discordgo.ApplicationCommandOption{searchOption("title", "Title of the manga")},
+	},
+	{
+		Name:        "staff",
+		Description: "Look up a staff member's profile",
+		Options:     []*discordgo.ApplicationCommandOption{searchOption("name", "Name of the staff member")},
+	},
+	{
+		Name:        "studio",
+		Description: "Look up a studio's profile and productions",
+		Options:     []*discordgo.ApplicationCommandOption{searchOption("name", "Name of the studio")},
+	},
+}
+
+func searchOption(name string, description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         name,
+		Description:  description,
+		Required:     true,
+		Autocomplete: true,
+	}
+}
+
+// registerCommands makes our slash commands available in every guild the bot is in.
+func registerCommands(s *discordgo.Session) error {
+	_, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", slashCommands)
+	return err
+}
+
+func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		slashCommand(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		autocomplete(s, i)
+	case discordgo.InteractionMessageComponent:
+		customID := i.MessageComponentData().CustomID
+		if strings.HasPrefix(customID, pagePrefix) {
+			pagePressed(s, i)
+		} else if strings.HasPrefix(customID, pickPrefix) {
+			pickPressed(s, i)
+		} else {
+			buttonPressed(s, i)
+		}
+	}
+}
+
+func slashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return
+	}
+	value := data.Options[0].StringValue()
+
+	// AniList can take longer than the 3 seconds Discord gives us to answer, so acknowledge first.
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err = slashLookup(context.Background(), s, Followup{i.Interaction}, interactionUser(i), data.Name, value)
+	if err != nil {
+		fmt.Println(err)
+		reply := userMessage(err)
+		if reply == "" {
+			reply = fmt.Sprintf("Sorry, I couldn't find anything for \"%s\".", value)
+		}
+		s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{Content: reply})
+	}
+}
+
+// slashLookup finds what a slash command asked for, and posts it to dest. Titles typed out rather than
+// picked from the suggestions are matched like any other lookup, so requester may be asked which they meant.
+func slashLookup(ctx context.Context, s *discordgo.Session, dest Destination, requester string, command string, value string) error {
+	id, byID := choiceID(value)
+
+	switch command {
+	case "anime", "manga":
+		if !byID {
+			return sendTitleMatch(ctx, s, dest, requester, anilist.MediaQuery{
+				Title:   value,
+				Type:    anilist.MediaType(strings.ToUpper(command)),
+				IsAdult: settings.get(dest.guild(s)).adultFilter(),
+			})
+		}
+		media, err := ani.MediaFromMediaID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return Send(s, dest, media)
+	case "staff":
+		var staff []anilist.Staff
+		var err error
+		if byID {
+			var person anilist.Staff
+			person, err = ani.StaffFromPersonID(ctx, id)
+			staff = []anilist.Staff{person}
+		} else {
+			staff, err = ani.StaffFromPersonName(ctx, value, 1)
+		}
+		if err != nil {
+			return err
+		}
+		if len(staff) == 0 {
+			return errNoResults
+		}
+		return SendStaff(s, dest, staff[0])
+	case "studio":
+		if byID {
+			return SendPage(s, dest, "studio", strconv.Itoa(id), 1)
+		}
+		studio, err := ani.StudioDetailFromStudioQuery(ctx, anilist.StudioQuery{Name: value, PerPage: productionsPerPage})
+		if err != nil {
+			return err
+		}
+		if studio.ID == 0 {
+			return errNoResults
+		}
+		return SendStudio(s, dest, studio)
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+
+	var partial string
+	for _, option := range data.Options {
+		if option.Focused {
+			partial = option.StringValue()
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if strings.TrimSpace(partial) != "" {
+		var err error
+		ctx := withSettings(context.Background(), settings.get(i.GuildID))
+		choices, err = autocompleteChoices(ctx, data.Name, partial)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func autocompleteChoices(ctx context.Context, command string, partial string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+
+	switch command {
+	case "anime", "manga":
+		medias, err := ani.MediaFromMediaQuery(ctx, anilist.MediaQuery{
+			Title:   partial,
+			Type:    anilist.MediaType(strings.ToUpper(command)),
+			Sort:    []anilist.MediaSort{anilist.MediaSortSearchMatch},
+			IsAdult: settingsFrom(ctx).adultFilter(),
+			Fields:  anilist.MediaFieldBasic,
+			PerPage: maxChoices,
+		})
+		if err != nil {
+			return choices, err
+		}
+		for _, media := range medias {
+			choices = append(choices, choice(media.Title.Romaji, media.ID))
+		}
+	case "staff":
+		people, err := ani.PeopleFromName(ctx, partial, maxChoices)
+		if err != nil {
+			return choices, err
+		}
+		for _, person := range people {
+			choices = append(choices, choice(strings.TrimSpace(person.Name.First+" "+person.Name.Last), person.ID))
+		}
+	case "studio":
+		studios, err := ani.StudiosFromName(ctx, partial, maxChoices)
+		if err != nil {
+			return choices, err
+		}
+		for _, studio := range studios {
+			choices = append(choices, choice(studio.Name, studio.ID))
+		}
+	}
+	return choices, nil
+}
+
+func choice(name string, id int) *discordgo.ApplicationCommandOptionChoice {
+	return &discordgo.ApplicationCommandOptionChoice{
+		Name:  truncate(name, maxChoiceNameSize),
+		Value: idChoicePrefix + strconv.Itoa(id),
+	}
+}
+
+// choiceID extracts the AniList ID from a value picked out of the autocomplete list.
+func choiceID(value string) (int, bool) {
+	if !strings.HasPrefix(value, idChoicePrefix) {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(value, idChoicePrefix))
+	return id, err == nil
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	productionsPerPage = 10
+	airingPerPage      = 15
+	seasonPerPage      = 15
+)
+
+// Navigation buttons on paginated embeds have custom IDs of the form "page:<kind>:<page>:<arg>".
+// Everything needed to render a page lives in the custom ID, so they keep working across restarts.
+const pagePrefix = "page:"
+
+// pageRenderer renders one page of a paginated embed. arg identifies what is being paged through.
+// Any rows of components it returns are shown above the page controls. current is the page it rendered,
+// which may not be the one asked for when that's out of range.
+type pageRenderer func(ctx context.Context, arg string, page int) (embed discordgo.MessageEmbed, rows []discordgo.MessageComponent, current int, lastPage int, err error)
+
+var pagers = map[string]pageRenderer{
+	"studio": studioPage,
+	"airing": airingPage,
+	"season": seasonPage,
+	"search": searchPage,
+	"find":   findPage,
+}
+
+func pageCustomID(kind string, page int, arg string) string {
+	return fmt.Sprintf("%s%s:%d:%s", pagePrefix, kind, page, arg)
+}
+
+func parsePageCustomID(customID string) (kind string, page int, arg string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(customID, pagePrefix), ":", 3)
+	if len(parts) != 3 {
+		return "", 0, "", fmt.Errorf("malformed page button ID %q", customID)
+	}
+	page, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, "", fmt.Errorf("malformed page button ID %q", customID)
+	}
+	return parts[0], page, parts[2], nil
+}
+
+// pageControls builds the previous/next buttons for a paginated embed, below any other rows the page has.
+func pageControls(kind string, arg string, page int, lastPage int, rows ...discordgo.MessageComponent) []discordgo.MessageComponent {
+	// An empty list rather than nil, so editing a message to this page clears any old controls.
+	components := append([]discordgo.MessageComponent{}, rows...)
+	if lastPage <= 1 {
+		return components
+	}
+	return append(components,
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: pageCustomID(kind, page-1, arg),
+					Disabled: page <= 1,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					CustomID: pageCustomID(kind, page+1, arg),
+					Disabled: page >= lastPage,
+				},
+			},
+		},
+	)
+}
+
+// renderPage renders page with render. Pages past the end, e.g. from the controls of a listing that has
+// since shrunk, are rendered as the last page instead.
+func renderPage(ctx context.Context, render pageRenderer, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	embed, rows, current, lastPage, err := render(ctx, arg, page)
+	if err == nil && current > lastPage && lastPage >= 1 {
+		return render(ctx, arg, lastPage)
+	}
+	return embed, rows, current, lastPage, err
+}
+
+// SendPage sends the given page of a paginated embed to dest, along with controls to flip through the rest.
+func SendPage(s *discordgo.Session, dest Destination, kind string, arg string, page int) error {
+	render, ok := pagers[kind]
+	if !ok {
+		return fmt.Errorf("unknown paginated embed %q", kind)
+	}
+
+	ctx := withSettings(context.Background(), settings.get(dest.guild(s)))
+	embed, rows, current, lastPage, err := renderPage(ctx, render, arg, page)
+	if err != nil {
+		return err
+	}
+	_, err = dest.post(s, &embed, pageControls(kind, arg, current, lastPage, rows...))
+	return err
+}
+
+// pagePressed flips a paginated embed to the page its navigation button points at.
+func pagePressed(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	kind, page, arg, err := parsePageCustomID(i.MessageComponentData().CustomID)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	render, ok := pagers[kind]
+	if !ok {
+		fmt.Printf("Unknown paginated embed %q\n", kind)
+		return
+	}
+
+	ctx := withSettings(context.Background(), settings.get(i.GuildID))
+	embed, rows, current, lastPage, err := renderPage(ctx, render, arg, page)
+	if err != nil {
+		reply := userMessage(err)
+		if reply == "" {
+			reply = "Sorry, I couldn't load that page."
+		}
+		fmt.Println(err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: reply,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{&embed},
+			Components: pageControls(kind, arg, current, lastPage, rows...),
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func studioPage(ctx context.Context, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+	studio, err := ani.StudioDetailFromStudioQuery(ctx, anilist.StudioQuery{ID: id, Page: page, PerPage: productionsPerPage})
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+	embed, err := StudioEmbed(studio)
+	return embed, nil, studio.Media.PageInfo.CurrentPage, studio.Media.PageInfo.LastPage, err
+}
+
+// pageBounds splits total items into pages of perPage, returning the slice bounds of page.
+// Pages out of range are clamped to the first or last, and current is the page the bounds are for.
+func pageBounds(total int, perPage int, page int) (current int, lastPage int, start int, end int) {
+	lastPage = (total + perPage - 1) / perPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > lastPage {
+		page = lastPage
+	}
+	start = (page - 1) * perPage
+	end = start + perPage
+	if end > total {
+		end = total
+	}
+	return page, lastPage, start, end
+}
+
+// airingArg identifies an airing schedule listing: the window starting at from and lasting days,
+// optionally restricted to shows from the season that from falls in.
+func airingArg(from time.Time, days int, seasonOnly bool) string {
+	return fmt.Sprintf("%d,%d,%t", from.Unix(), days, seasonOnly)
+}
+
+func airingPage(ctx context.Context, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	var unix int64
+	var days int
+	var seasonOnly bool
+	if _, err := fmt.Sscanf(arg, "%d,%d,%t", &unix, &days, &seasonOnly); err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, fmt.Errorf("malformed airing listing %q: %v", arg, err)
+	}
+	from := time.Unix(unix, 0)
+
+	schedules, err := ani.AiringSchedulesBetween(ctx, from, from.Add(time.Duration(days)*24*time.Hour))
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+
+	season, year := anilist.Season(from)
+	heading := "Airing today"
+	if days > 1 {
+		heading = "Airing this week"
+	}
+	if seasonOnly {
+		heading = fmt.Sprintf("%s (%s %d)", heading, strings.Title(strings.ToLower(season.String())), year)
+	}
+
+	adult := settingsFrom(ctx).Adult
+	var listed []anilist.AiringSchedule
+	for _, schedule := range schedules {
+		if schedule.Media.IsAdult && !adult {
+			continue
+		}
+		if seasonOnly && (schedule.Media.Season != season || schedule.Media.SeasonYear != year) {
+			continue
+		}
+		listed = append(listed, schedule)
+	}
+
+	page, lastPage, start, end := pageBounds(len(listed), airingPerPage, page)
+	embed, err := AiringEmbed(heading, listed[start:end], days > 1, page, lastPage)
+	return embed, nil, page, lastPage, err
+}
+
+// seasonArg identifies a seasonal chart, e.g. "SPRING,2021".
+func seasonArg(season anilist.MediaSeason, year int) string {
+	return fmt.Sprintf("%s,%d", season, year)
+}
+
+func seasonPage(ctx context.Context, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	parts := strings.SplitN(arg, ",", 2)
+	if len(parts) != 2 {
+		return discordgo.MessageEmbed{}, nil, 0, 0, fmt.Errorf("malformed seasonal chart %q", arg)
+	}
+	season := anilist.MediaSeason(parts[0])
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, fmt.Errorf("malformed seasonal chart %q: %v", arg, err)
+	}
+
+	medias, pageInfo, err := ani.MediaFromSeason(ctx, season, year, settingsFrom(ctx).adultFilter(), page, seasonPerPage)
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+	heading := fmt.Sprintf("%s %d", strings.Title(strings.ToLower(season.String())), year)
+	embed, err := SeasonEmbed(heading, medias, pageInfo)
+	return embed, nil, pageInfo.CurrentPage, pageInfo.LastPage, err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		total, perPage, page          int
+		current, lastPage, start, end int
+	}{
+		{0, 15, 1, 1, 1, 0, 0},
+		{10, 15, 1, 1, 1, 0, 10},
+		{30, 15, 2, 2, 2, 15, 30},
+		{31, 15, 3, 3, 3, 30, 31},
+		// Out of range pages are clamped, rather than starting over.
+		{31, 15, 0, 1, 3, 0, 15},
+		{31, 15, -2, 1, 3, 0, 15},
+		{31, 15, 5, 3, 3, 30, 31},
+		{0, 15, 2, 1, 1, 0, 0},
+	}
+	for _, test := range tests {
+		current, lastPage, start, end := pageBounds(test.total, test.perPage, test.page)
+		if current != test.current || lastPage != test.lastPage || start != test.start || end != test.end {
+			t.Errorf("pageBounds(%d, %d, %d) = %d, %d, %d, %d, want %d, %d, %d, %d",
+				test.total, test.perPage, test.page, current, lastPage, start, end,
+				test.current, test.lastPage, test.start, test.end)
+		}
+	}
+}
+
+func TestRenderPagePastTheEnd(t *testing.T) {
+	var asked []int
+	// Like AniList, this renders whatever page it's asked for, even one past the end.
+	render := func(ctx context.Context, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+		asked = append(asked, page)
+		return discordgo.MessageEmbed{}, nil, page, 2, nil
+	}
+
+	_, _, current, lastPage, err := renderPage(context.Background(), render, "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != 2 || lastPage != 2 {
+		t.Errorf("rendered page %d of %d, want 2 of 2", current, lastPage)
+	}
+	if len(asked) != 2 || asked[1] != 2 {
+		t.Errorf("expected the last page to be rendered after page 5, rendered %v", asked)
+	}
+
+	asked = nil
+	if _, _, current, _, _ := renderPage(context.Background(), render, "", 1); current != 1 || len(asked) != 1 {
+		t.Errorf("page 1 should be rendered once, got page %d after %v", current, asked)
+	}
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+)
+
+// The find command takes a small query language: space separated filters of the form key:value,
+// or key followed by a comparison for numbers (year>2015, score>=80), and any other words are searched
+// for in titles. Values with spaces go in double quotes, e.g. genre:"slice of life", and a filter can be
+// negated with a leading "-" where that makes sense (-genre:horror).
+
+// findFilter is one of the keys the find command understands.
+type findFilter struct {
+	usage string
+	// negate is whether the filter can be negated, and compare whether it takes >, >=, < and <=.
+	negate  bool
+	compare bool
+	// apply narrows query down with the filter. op is one of ":", "=", ">", ">=", "<" or "<=".
+	apply func(query *anilist.MediaQuery, op string, value string, negated bool) error
+}
+
+// findTerm is one space separated part of a find expression.
+type findTerm struct {
+	text string
+	// quoted terms are always part of the title, even if they look like a filter.
+	quoted bool
+}
+
+// Genres are a fixed set on AniList, and are matched case-sensitively, so we normalize to these.
+var knownGenres = []string{
+	"Action", "Adventure", "Comedy", "Drama", "Ecchi", "Fantasy", "Horror", "Mahou Shoujo", "Mecha", "Music",
+	"Mystery", "Psychological", "Romance", "Sci-Fi", "Slice of Life", "Sports", "Supernatural", "Thriller",
+}
+
+var knownFormats = map[string]string{
+	"tv":       "TV",
+	"short":    "TV_SHORT",
+	"tv_short": "TV_SHORT",
+	"movie":    "MOVIE",
+	"special":  "SPECIAL",
+	"ova":      "OVA",
+	"ona":      "ONA",
+	"music":    "MUSIC",
+	"manga":    "MANGA",
+	"novel":    "NOVEL",
+	"oneshot":  "ONE_SHOT",
+	"one_shot": "ONE_SHOT",
+}
+
+var knownStatuses = map[string]string{
+	"finished":         "FINISHED",
+	"releasing":        "RELEASING",
+	"airing":           "RELEASING",
+	"upcoming":         "NOT_YET_RELEASED",
+	"not_yet_released": "NOT_YET_RELEASED",
+	"cancelled":        "CANCELLED",
+	"hiatus":           "HIATUS",
+}
+
+var knownSeasons = map[string]string{
+	"winter": "WINTER",
+	"spring": "SPRING",
+	"summer": "SUMMER",
+	"fall":   "FALL",
+	"autumn": "FALL",
+}
+
+var knownSources = map[string]string{
+	"original":     "ORIGINAL",
+	"manga":        "MANGA",
+	"light_novel":  "LIGHT_NOVEL",
+	"novel":        "NOVEL",
+	"visual_novel": "VISUAL_NOVEL",
+	"game":         "VIDEO_GAME",
+	"video_game":   "VIDEO_GAME",
+	"anime":        "ANIME",
+	"doujinshi":    "DOUJINSHI",
+	"web_novel":    "WEB_NOVEL",
+	"live_action":  "LIVE_ACTION",
+	"comic":        "COMIC",
+	"picture_book": "PICTURE_BOOK",
+	"multimedia":   "MULTIMEDIA_PROJECT",
+	"other":        "OTHER",
+}
+
+var knownSorts = map[string]string{
+	"popular":    "POPULARITY_DESC",
+	"score":      "SCORE_DESC",
+	"top":        "SCORE_DESC",
+	"trending":   "TRENDING_DESC",
+	"newest":     "START_DATE_DESC",
+	"oldest":     "START_DATE",
+	"title":      "TITLE_ROMAJI",
+	"favourites": "FAVOURITES_DESC",
+	"favorites":  "FAVOURITES_DESC",
+	"relevance":  "SEARCH_MATCH",
+}
+
+var knownCountries = map[string]string{
+	"jp": "JP",
+	"kr": "KR",
+	"cn": "CN",
+	"tw": "TW",
+}
+
+// The ranges the numeric filters can take. MediaQuery treats 0 as unset, so nothing starts from 0.
+const (
+	minYear       = 1900
+	maxYear       = 2100
+	minScore      = 1
+	maxScore      = 100
+	minPopularity = 1
+	maxPopularity = math.MaxInt32
+)
+
+// findFilters are the keys the find command understands.
+var findFilters = map[string]findFilter{
+	"type": {
+		usage: "type:anime|manga",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			switch strings.ToLower(value) {
+			case "anime", "manga":
+				query.Type = anilist.MediaType(strings.ToUpper(value))
+				return nil
+			}
+			return fmt.Errorf("type must be anime or manga, not \"%s\"", value)
+		},
+	},
+	"genre": {
+		usage:  "genre:action,comedy",
+		negate: true,
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			for _, name := range strings.Split(value, ",") {
+				genre, ok := matchGenre(name)
+				if !ok {
+					return fmt.Errorf("\"%s\" isn't a genre, try one of: %s", name, strings.Join(knownGenres, ", "))
+				}
+				if negated {
+					query.ExcludedGenres = append(query.ExcludedGenres, genre)
+				} else {
+					query.Genres = append(query.Genres, genre)
+				}
+			}
+			return nil
+		},
+	},
+	"tag": {
+		usage: `tag:isekai,"time travel"`,
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			for _, tag := range strings.Split(value, ",") {
+				// Nearly every AniList tag is in title case.
+				query.Tags = append(query.Tags, strings.Title(strings.ToLower(strings.TrimSpace(tag))))
+			}
+			return nil
+		},
+	},
+	"format": {
+		usage: "format:tv,movie",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			for _, name := range strings.Split(value, ",") {
+				format, err := lookup("format", knownFormats, name)
+				if err != nil {
+					return err
+				}
+				query.Formats = append(query.Formats, anilist.MediaFormat(format))
+			}
+			return nil
+		},
+	},
+	"status": {
+		usage: "status:airing",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			status, err := lookup("status", knownStatuses, value)
+			query.Status = anilist.MediaStatus(status)
+			return err
+		},
+	},
+	"season": {
+		usage: "season:spring",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			season, err := lookup("season", knownSeasons, value)
+			query.Season = anilist.MediaSeason(season)
+			return err
+		},
+	},
+	"source": {
+		usage: "source:light_novel",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			source, err := lookup("source", knownSources, value)
+			query.Source = anilist.MediaSource(source)
+			return err
+		},
+	},
+	"country": {
+		usage: "country:kr",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) (err error) {
+			query.CountryOfOrigin, err = lookup("country", knownCountries, value)
+			return
+		},
+	},
+	"sort": {
+		usage: "sort:popular",
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			order, err := lookup("sort", knownSorts, value)
+			query.Sort = []anilist.MediaSort{anilist.MediaSort(order)}
+			return err
+		},
+	},
+	"year": {
+		usage:   "year:2019 or year>=2015",
+		compare: true,
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			year, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("\"%s\" isn't a year", value)
+			}
+			from, to, ok := bounds(op, year, minYear, maxYear)
+			if !ok {
+				return fmt.Errorf("years go from %d to %d, so year%s%d can't match anything", minYear, maxYear, op, year)
+			}
+			if from != minYear {
+				query.StartDateFrom = anilist.FuzzyDate{Year: from}
+			}
+			if to != maxYear {
+				query.StartDateTo = anilist.FuzzyDate{Year: to}
+			}
+			return nil
+		},
+	},
+	"score": {
+		usage:   "score>80",
+		compare: true,
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			score, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil {
+				return fmt.Errorf("score is out of 100, so \"%s\" doesn't work", value)
+			}
+			from, to, ok := bounds(op, score, minScore, maxScore)
+			if !ok {
+				return fmt.Errorf("scores go from %d to %d, so score%s%d can't match anything", minScore, maxScore, op, score)
+			}
+			if from != minScore {
+				query.MinScore = from
+			}
+			if to != maxScore {
+				query.MaxScore = to
+			}
+			return nil
+		},
+	},
+	"popularity": {
+		usage:   "popularity>10000",
+		compare: true,
+		apply: func(query *anilist.MediaQuery, op string, value string, negated bool) error {
+			popularity, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("popularity is a number of users, so \"%s\" doesn't work", value)
+			}
+			from, to, ok := bounds(op, popularity, minPopularity, maxPopularity)
+			if !ok {
+				return fmt.Errorf("popularity is a number of users from %d up, so popularity%s%d can't match anything", minPopularity, op, popularity)
+			}
+			if from != minPopularity {
+				query.MinPopularity = from
+			}
+			if to != maxPopularity {
+				query.MaxPopularity = to
+			}
+			return nil
+		},
+	},
+}
+
+// parseFind turns a find expression into the MediaQuery it describes. Its errors are meant to be shown to users.
+func parseFind(expression string) (anilist.MediaQuery, error) {
+	var query anilist.MediaQuery
+	var title []string
+
+	terms, err := splitTerms(expression)
+	if err != nil {
+		return query, err
+	}
+	for _, term := range terms {
+		key, op, value, ok := splitFilter(term.text)
+		if !ok || term.quoted {
+			title = append(title, term.text)
+			continue
+		}
+
+		negated := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		filter, known := findFilters[key]
+		if !known {
+			return query, fmt.Errorf("I don't know the filter \"%s\". Filters are: %s. Put titles containing \"%s\" in quotes", key, strings.Join(findFilterNames(), ", "), op)
+		}
+		if negated && !filter.negate {
+			return query, fmt.Errorf("%s can't be negated", key)
+		}
+		if op != ":" && op != "=" && !filter.compare {
+			return query, fmt.Errorf("%s can't be compared with %s, use it like %s", key, op, filter.usage)
+		}
+		if value == "" {
+			return query, fmt.Errorf("%s needs a value, use it like %s", key, filter.usage)
+		}
+		if err := filter.apply(&query, op, value, negated); err != nil {
+			return query, err
+		}
+	}
+
+	query.Title = strings.Join(title, " ")
+	if len(terms) == 0 {
+		return query, fmt.Errorf("tell me what to look for, e.g. `genre:action year:2019 sort:popular`")
+	}
+	if len(query.Sort) == 0 {
+		query.Sort = []anilist.MediaSort{anilist.MediaSortPopularityDesc}
+		if query.Title != "" {
+			query.Sort = []anilist.MediaSort{anilist.MediaSortSearchMatch}
+		}
+	} else if query.Sort[0] == anilist.MediaSortSearchMatch && query.Title == "" {
+		return query, fmt.Errorf("sort:relevance only works when searching for a title")
+	}
+	return query, nil
+}
+
+// splitTerms splits expression on spaces, keeping anything in double quotes together.
+func splitTerms(expression string) ([]findTerm, error) {
+	var terms []findTerm
+	var term strings.Builder
+	var current findTerm
+	quoting, inTerm := false, false
+	for _, r := range expression {
+		switch {
+		case r == '"':
+			if !inTerm {
+				current.quoted = true
+			}
+			quoting = !quoting
+			inTerm = true
+		case unicode.IsSpace(r) && !quoting:
+			if inTerm {
+				current.text = term.String()
+				terms = append(terms, current)
+				term.Reset()
+				current = findTerm{}
+				inTerm = false
+			}
+		default:
+			term.WriteRune(r)
+			inTerm = true
+		}
+	}
+	if quoting {
+		return nil, fmt.Errorf("there's a \" without a closing \"")
+	}
+	if inTerm {
+		current.text = term.String()
+		terms = append(terms, current)
+	}
+	return terms, nil
+}
+
+// splitFilter splits a term like "score>=80" into its key, operator and value. Terms that don't start
+// with a word followed by an operator aren't filters.
+func splitFilter(term string) (key string, op string, value string, ok bool) {
+	end := strings.IndexAny(term, ":=<>")
+	if end <= 0 {
+		return "", "", "", false
+	}
+	key = strings.ToLower(term[:end])
+	name := strings.TrimPrefix(key, "-")
+	if name == "" {
+		return "", "", "", false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return "", "", "", false
+		}
+	}
+
+	op = term[end : end+1]
+	if (op == "<" || op == ">") && strings.HasPrefix(term[end+1:], "=") {
+		op += "="
+	}
+	return key, op, strings.TrimSpace(term[end+len(op):]), true
+}
+
+// bounds is the inclusive range of values from min to max that compare to n with op. It isn't ok
+// if the range reaches outside min and max or is empty, since then the filter can't match anything.
+func bounds(op string, n int, min int, max int) (from int, to int, ok bool) {
+	from, to = min, max
+	switch op {
+	case ">":
+		from = n + 1
+	case ">=":
+		from = n
+	case "<":
+		to = n - 1
+	case "<=":
+		to = n
+	default:
+		from, to = n, n
+	}
+	return from, to, from >= min && to <= max && from <= to
+}
+
+// lookup finds the AniList value for a user's name for it, accepting spaces and dashes in place of underscores.
+func lookup(key string, values map[string]string, name string) (string, error) {
+	normalized := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+	if value, ok := values[normalized]; ok {
+		return value, nil
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("\"%s\" isn't a %s, try one of: %s", name, key, strings.Join(names, ", "))
+}
+
+// matchGenre finds the genre name is referring to, ignoring case, spaces and dashes.
+func matchGenre(name string) (string, bool) {
+	squash := strings.NewReplacer(" ", "", "-", "", "_", "")
+	name = squash.Replace(strings.ToLower(name))
+	for _, genre := range knownGenres {
+		if squash.Replace(strings.ToLower(genre)) == name {
+			return genre, true
+		}
+	}
+	return "", false
+}
+
+func findFilterNames() []string {
+	names := make([]string, 0, len(findFilters))
+	for name := range findFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+)
+
+func TestParseFind(t *testing.T) {
+	popular := []anilist.MediaSort{anilist.MediaSortPopularityDesc}
+	relevant := []anilist.MediaSort{anilist.MediaSortSearchMatch}
+
+	tests := []struct {
+		expression string
+		want       anilist.MediaQuery
+	}{
+		{"genre:action", anilist.MediaQuery{Genres: []string{"Action"}, Sort: popular}},
+		{"genre:action,sci-fi", anilist.MediaQuery{Genres: []string{"Action", "Sci-Fi"}, Sort: popular}},
+		{`genre:"slice of life"`, anilist.MediaQuery{Genres: []string{"Slice of Life"}, Sort: popular}},
+		{"-genre:horror", anilist.MediaQuery{ExcludedGenres: []string{"Horror"}, Sort: popular}},
+		{"GENRE:ACTION", anilist.MediaQuery{Genres: []string{"Action"}, Sort: popular}},
+		{`tag:isekai,"time travel"`, anilist.MediaQuery{Tags: []string{"Isekai", "Time Travel"}, Sort: popular}},
+		{"type:manga", anilist.MediaQuery{Type: anilist.MediaTypeManga, Sort: popular}},
+		{"format:tv,movie", anilist.MediaQuery{Formats: []anilist.MediaFormat{anilist.MediaFormatTV, anilist.MediaFormatMovie}, Sort: popular}},
+		{"status:airing", anilist.MediaQuery{Status: anilist.MediaStatusReleasing, Sort: popular}},
+		{"season:autumn", anilist.MediaQuery{Season: anilist.MediaSeasonFall, Sort: popular}},
+		{"source:light-novel", anilist.MediaQuery{Source: anilist.MediaSourceLightNovel, Sort: popular}},
+		{"country:kr", anilist.MediaQuery{CountryOfOrigin: "KR", Sort: popular}},
+		{"sort:top", anilist.MediaQuery{Sort: []anilist.MediaSort{anilist.MediaSortScoreDesc}}},
+
+		{"year:2019", anilist.MediaQuery{StartDateFrom: anilist.FuzzyDate{Year: 2019}, StartDateTo: anilist.FuzzyDate{Year: 2019}, Sort: popular}},
+		{"year=2019", anilist.MediaQuery{StartDateFrom: anilist.FuzzyDate{Year: 2019}, StartDateTo: anilist.FuzzyDate{Year: 2019}, Sort: popular}},
+		{"year>2015", anilist.MediaQuery{StartDateFrom: anilist.FuzzyDate{Year: 2016}, Sort: popular}},
+		{"year>=2015", anilist.MediaQuery{StartDateFrom: anilist.FuzzyDate{Year: 2015}, Sort: popular}},
+		{"year<2015", anilist.MediaQuery{StartDateTo: anilist.FuzzyDate{Year: 2014}, Sort: popular}},
+		{"year<=2015", anilist.MediaQuery{StartDateTo: anilist.FuzzyDate{Year: 2015}, Sort: popular}},
+		{"score>80", anilist.MediaQuery{MinScore: 81, Sort: popular}},
+		{"score>=80%", anilist.MediaQuery{MinScore: 80, Sort: popular}},
+		{"score<50", anilist.MediaQuery{MaxScore: 49, Sort: popular}},
+		{"score<=50", anilist.MediaQuery{MaxScore: 50, Sort: popular}},
+		{"score>99", anilist.MediaQuery{MinScore: 100, Sort: popular}},
+		{"score<2", anilist.MediaQuery{MaxScore: 1, Sort: popular}},
+		{"score:100", anilist.MediaQuery{MinScore: 100, Sort: popular}},
+		{"popularity>10000", anilist.MediaQuery{MinPopularity: 10001, Sort: popular}},
+		{"popularity<=500", anilist.MediaQuery{MaxPopularity: 500, Sort: popular}},
+
+		{"cowboy bebop", anilist.MediaQuery{Title: "cowboy bebop", Sort: relevant}},
+		{`"re:zero" type:anime`, anilist.MediaQuery{Title: "re:zero", Type: anilist.MediaTypeAnime, Sort: relevant}},
+		{`"steins;gate 0" sort:popular`, anilist.MediaQuery{Title: "steins;gate 0", Sort: popular}},
+		{"bebop sort:relevance", anilist.MediaQuery{Title: "bebop", Sort: relevant}},
+		{"86 year>2020", anilist.MediaQuery{Title: "86", StartDateFrom: anilist.FuzzyDate{Year: 2021}, Sort: relevant}},
+		{"  genre:mecha   year:1995  ", anilist.MediaQuery{Genres: []string{"Mecha"}, StartDateFrom: anilist.FuzzyDate{Year: 1995}, StartDateTo: anilist.FuzzyDate{Year: 1995}, Sort: popular}},
+	}
+
+	for _, test := range tests {
+		got, err := parseFind(test.expression)
+		if err != nil {
+			t.Errorf("parseFind(%q): %v", test.expression, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseFind(%q)\n got %+v\nwant %+v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestParseFindErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		// mentions is part of the error users should see.
+		mentions string
+	}{
+		{"", "tell me what to look for"},
+		{"studio:sunrise", `don't know the filter "studio"`},
+		{"genre:sports,cooking", `"cooking" isn't a genre`},
+		{"format:film", `"film" isn't a format`},
+		{"status:done", `"done" isn't a status`},
+		{"season:monsoon", `"monsoon" isn't a season`},
+		{"sort:random", `"random" isn't a sort`},
+		{"type:novel", "type must be anime or manga"},
+		{"type:", "type needs a value"},
+		{"-type:anime", "type can't be negated"},
+		{"-year:2019", "year can't be negated"},
+		{"genre>action", "genre can't be compared with >"},
+		{"sort<=top", "sort can't be compared with <="},
+		{`genre:"slice of life`, `there's a " without a closing "`},
+		{"sort:relevance genre:action", "sort:relevance only works when searching for a title"},
+
+		{"year:nineties", `"nineties" isn't a year`},
+		{"year:1800", "year:1800 can't match anything"},
+		{"year>2100", "year>2100 can't match anything"},
+		{"year<1900", "year<1900 can't match anything"},
+		{"score:abc", `"abc" doesn't work`},
+		{"score>100", "score>100 can't match anything"},
+		{"score>=101", "score>=101 can't match anything"},
+		{"score<1", "score<1 can't match anything"},
+		{"score<=0", "score<=0 can't match anything"},
+		{"score:0", "score:0 can't match anything"},
+		{"popularity:lots", `"lots" doesn't work`},
+		{"popularity<1", "popularity<1 can't match anything"},
+	}
+
+	for _, test := range tests {
+		_, err := parseFind(test.expression)
+		if err == nil {
+			t.Errorf("parseFind(%q) should have failed", test.expression)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.mentions) {
+			t.Errorf("parseFind(%q) = %q, should mention %q", test.expression, err, test.mentions)
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		op       string
+		n        int
+		from, to int
+		ok       bool
+	}{
+		{":", 50, 50, 50, true},
+		{"=", 50, 50, 50, true},
+		{">", 50, 51, 100, true},
+		{">=", 50, 50, 100, true},
+		{"<", 50, 1, 49, true},
+		{"<=", 50, 1, 50, true},
+		{">", 100, 101, 100, false},
+		{"<", 1, 1, 0, false},
+		{">=", 1, 1, 100, true},
+		{"<=", 100, 1, 100, true},
+		{":", 101, 101, 101, false},
+		{">", -5, -4, 100, false},
+	}
+	for _, test := range tests {
+		from, to, ok := bounds(test.op, test.n, 1, 100)
+		if from != test.from || to != test.to || ok != test.ok {
+			t.Errorf("bounds(%q, %d, 1, 100) = %d, %d, %v, want %d, %d, %v", test.op, test.n, from, to, ok, test.from, test.to, test.ok)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// One row of pick buttons per page.
+	searchPerPage = buttonsPerRow
+	// Queries have to fit in the page buttons' custom IDs, which Discord caps at 100 characters.
+	maxSearchLength = 50
+	maxFindLength   = 60
+	// How sure a title lookup has to be before the best match is posted outright, rather than asking which was meant.
+	confidentMatch = 0.7
+)
+
+// Pick buttons on search results have custom IDs of the form "pick:<requester>:<media ID>".
+const pickPrefix = "pick:"
+
+// searchArg identifies a search: who asked for it, what type of media they want ("" for either), and the query.
+func searchArg(requester string, mediaType anilist.MediaType, query string) string {
+	return fmt.Sprintf("%s,%s,%s", requester, mediaType, query)
+}
+
+func searchPage(ctx context.Context, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	parts := strings.SplitN(arg, ",", 3)
+	if len(parts) != 3 {
+		return discordgo.MessageEmbed{}, nil, 0, 0, fmt.Errorf("malformed search %q", arg)
+	}
+	requester, mediaType, query := parts[0], parts[1], parts[2]
+
+	heading := fmt.Sprintf("Results for \"%s\"", query)
+	return resultsPage(ctx, requester, heading, anilist.MediaQuery{Title: query, Type: anilist.MediaType(mediaType), Sort: []anilist.MediaSort{anilist.MediaSortSearchMatch}}, page)
+}
+
+// findArg identifies a find command's results: who asked for them, and the expression they used.
+func findArg(requester string, expression string) string {
+	return fmt.Sprintf("%s,%s", requester, expression)
+}
+
+func findPage(ctx context.Context, arg string, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	parts := strings.SplitN(arg, ",", 2)
+	if len(parts) != 2 {
+		return discordgo.MessageEmbed{}, nil, 0, 0, fmt.Errorf("malformed find %q", arg)
+	}
+	requester, expression := parts[0], parts[1]
+
+	query, err := parseFind(expression)
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+	return resultsPage(ctx, requester, fmt.Sprintf("Results for `%s`", expression), query, page)
+}
+
+// resultsPage lists one page of the media matching query, with a button for requester to pick each one.
+func resultsPage(ctx context.Context, requester string, heading string, query anilist.MediaQuery, page int) (discordgo.MessageEmbed, []discordgo.MessageComponent, int, int, error) {
+	query.IsAdult = settingsFrom(ctx).adultFilter()
+	query.Page = page
+	query.PerPage = searchPerPage
+	// Results only list titles, formats and years.
+	query.Fields = anilist.MediaFieldDates
+
+	medias, pageInfo, err := ani.MediaPageFromMediaQuery(ctx, query)
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+	lastPage := pageInfo.LastPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+	first := (pageInfo.CurrentPage-1)*searchPerPage + 1
+
+	embed, err := SearchEmbed(heading, medias, first, pageInfo.CurrentPage, lastPage)
+	if err != nil {
+		return discordgo.MessageEmbed{}, nil, 0, 0, err
+	}
+
+	return embed, pickRows(requester, medias, first), pageInfo.CurrentPage, lastPage, nil
+}
+
+// pickRows are the buttons for requester to pick one of medias, numbered from first.
+func pickRows(requester string, medias []anilist.Media, first int) []discordgo.MessageComponent {
+	var picks discordgo.ActionsRow
+	for i, media := range medias {
+		picks.Components = append(picks.Components, discordgo.Button{
+			Label:    strconv.Itoa(first + i),
+			Style:    discordgo.PrimaryButton,
+			CustomID: fmt.Sprintf("%s%s:%d", pickPrefix, requester, media.ID),
+		})
+	}
+	var rows []discordgo.MessageComponent
+	if len(picks.Components) > 0 {
+		rows = append(rows, picks)
+	}
+	return rows
+}
+
+// sendTitleMatch posts the media best matching query.Title to dest. When nothing matches closely enough,
+// it lists the likeliest candidates instead and lets requester pick the one they meant.
+func sendTitleMatch(ctx context.Context, s *discordgo.Session, dest Destination, requester string, query anilist.MediaQuery) error {
+	matches, err := ani.MatchTitle(ctx, query)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return notFoundError{query.Title}
+	}
+	if matches[0].Confidence >= confidentMatch {
+		return Send(s, dest, matches[0].Media)
+	}
+
+	suggestions := make([]anilist.Media, 0, searchPerPage)
+	for _, match := range matches {
+		if len(suggestions) == searchPerPage {
+			break
+		}
+		suggestions = append(suggestions, match.Media)
+	}
+	embed, err := SearchEmbed(fmt.Sprintf("Couldn't find \"%s\", did you mean…", query.Title), suggestions, 1, 1, 1)
+	if err != nil {
+		return err
+	}
+	_, err = dest.post(s, &embed, pickRows(requester, suggestions, 1))
+	return err
+}
+
+func searchCommand(s *discordgo.Session, channel string, requester string, mediaType anilist.MediaType, query string) error {
+	if len([]rune(query)) > maxSearchLength {
+		_, err := s.ChannelMessageSend(channel, fmt.Sprintf("Sorry, searches are limited to %d characters.", maxSearchLength))
+		return err
+	}
+	return SendPage(s, Channel(channel), "search", searchArg(requester, mediaType, query), 1)
+}
+
+func findCommand(s *discordgo.Session, channel string, requester string, expression string) error {
+	if len([]rune(expression)) > maxFindLength {
+		_, err := s.ChannelMessageSend(channel, fmt.Sprintf("Sorry, find is limited to %d characters.", maxFindLength))
+		return err
+	}
+	// Check the expression up front so mistakes get a helpful reply, rather than a failed lookup.
+	if _, err := parseFind(expression); err != nil {
+		return usageError{err.Error()}
+	}
+	return SendPage(s, Channel(channel), "find", findArg(requester, expression), 1)
+}
+
+// interactionUser is the ID of whoever triggered i, whether it happened in a server or a DM.
+func interactionUser(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// pickPressed expands a list of search results into the full embed for the chosen media.
+func pickPressed(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	parts := strings.SplitN(strings.TrimPrefix(customID, pickPrefix), ":", 2)
+	if len(parts) != 2 {
+		fmt.Printf("Malformed pick button ID %q\n", customID)
+		return
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Printf("Malformed pick button ID %q\n", customID)
+		return
+	}
+
+	if interactionUser(i) != parts[0] {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Only the person who searched can pick a result.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Looking up the full media can take longer than Discord waits for an answer.
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	media, err := ani.MediaFromMediaID(context.Background(), id)
+	if err != nil {
+		reportError(s, i.ChannelID, err)
+		return
+	}
+	guildSettings := settings.get(i.GuildID)
+	embed, err := mediaEmbed(media, guildSettings)
+	if err != nil {
+		reportError(s, i.ChannelID, err)
+		return
+	}
+
+	followUps := FollowUps(media, guildSettings.staffGroups())
+	// An empty list rather than nil, so the pick and page buttons are cleared.
+	components := append([]discordgo.MessageComponent{}, Components(followUps)...)
+	embeds := []*discordgo.MessageEmbed{&embed}
+	sent, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	buttons.register(sent, followUps)
+}
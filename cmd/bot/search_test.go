@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discordRequest is a request the bot made to Discord's API.
+type discordRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+// fakeDiscord stands in for Discord's API, answering every request with an empty object and remembering them.
+type fakeDiscord struct {
+	mu       sync.Mutex
+	requests []discordRequest
+}
+
+func (f *fakeDiscord) RoundTrip(r *http.Request) (*http.Response, error) {
+	request := discordRequest{method: r.Method, path: r.URL.Path}
+	if r.Body != nil {
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &request.body)
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	f.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+		Request:    r,
+	}, nil
+}
+
+// discordSession is a session whose requests go to a fakeDiscord instead of Discord.
+func discordSession(t *testing.T) (*discordgo.Session, *fakeDiscord) {
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeDiscord{}
+	s.Client = &http.Client{Transport: fake}
+	return s, fake
+}
+
+// pressed is an interaction for user pressing the button customID.
+func pressed(user string, customID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "interaction",
+		AppID:     "app",
+		Token:     "token",
+		Type:      discordgo.InteractionMessageComponent,
+		ChannelID: "channel",
+		Member:    &discordgo.Member{User: &discordgo.User{ID: user}},
+		Message:   &discordgo.Message{ID: "results", ChannelID: "channel"},
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customID, ComponentType: discordgo.ButtonComponent},
+	}}
+}
+
+func TestPickPressedByAnotherUser(t *testing.T) {
+	withMedia(t, `{"id":1,"type":"ANIME","title":{"romaji":"Cowboy Bebop"}}`)
+	s, discord := discordSession(t)
+
+	pickPressed(s, pressed("someone else", pickPrefix+"searcher:1"))
+
+	if len(discord.requests) != 1 {
+		t.Fatalf("expected a single reply, made %d requests: %v", len(discord.requests), discord.requests)
+	}
+	reply := discord.requests[0]
+	if reply.body["type"] != float64(discordgo.InteractionResponseChannelMessageWithSource) {
+		t.Errorf("expected a new message, got %v", reply.body)
+	}
+	data, _ := reply.body["data"].(map[string]interface{})
+	if data["flags"] != float64(discordgo.MessageFlagsEphemeral) || !strings.Contains(data["content"].(string), "Only the person who searched") {
+		t.Errorf("expected a private refusal, got %v", data)
+	}
+}
+
+func TestPickPressedBySearcher(t *testing.T) {
+	withMedia(t, `{"id":1,"type":"ANIME","title":{"romaji":"Cowboy Bebop"}}`)
+	s, discord := discordSession(t)
+
+	pickPressed(s, pressed("searcher", pickPrefix+"searcher:1"))
+
+	if len(discord.requests) != 2 {
+		t.Fatalf("expected the results to be deferred then replaced, made %d requests: %v", len(discord.requests), discord.requests)
+	}
+	if deferred := discord.requests[0].body["type"]; deferred != float64(discordgo.InteractionResponseDeferredMessageUpdate) {
+		t.Errorf("expected a deferred update first, got type %v", deferred)
+	}
+	edit := discord.requests[1]
+	if edit.method != http.MethodPatch || !strings.HasSuffix(edit.path, "/messages/@original") {
+		t.Errorf("expected the results to be edited, got %s %s", edit.method, edit.path)
+	}
+	embeds, _ := edit.body["embeds"].([]interface{})
+	if len(embeds) != 1 || !strings.Contains(embeds[0].(map[string]interface{})["title"].(string), "Cowboy Bebop") {
+		t.Errorf("expected the picked media's embed, got %v", edit.body["embeds"])
+	}
+}
+
+func TestPickPressedMalformed(t *testing.T) {
+	s, discord := discordSession(t)
+	for _, customID := range []string{pickPrefix + "searcher", pickPrefix + "searcher:one"} {
+		pickPressed(s, pressed("searcher", customID))
+	}
+	if len(discord.requests) != 0 {
+		t.Errorf("expected malformed picks to be ignored, made %v", discord.requests)
+	}
+}
@@ -3,110 +3,154 @@ package main
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/buckley-w-david/anibot/pkg/anilist"
-	"github.com/buckley-w-david/discordbuttons"
 	"github.com/bwmarrin/discordgo"
 )
 
-const expirationDelay = 60 * 60 * 24
+const expirationDelay = 24 * time.Hour
 
-func callAfterN(f func(), n time.Duration) {
-	time.Sleep(n * time.Second)
-	f()
+// Destination is somewhere we can post an embed.
+type Destination interface {
+	post(s *discordgo.Session, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) (*discordgo.Message, error)
+	// guild is the ID of the guild the destination is in, or "" outside of one.
+	guild(s *discordgo.Session) string
 }
 
-// Send an Embed message to the given channel using the provided Session.
-func Send(s *discordgo.Session, channel string, media anilist.Media) (err error) {
-	embed, err := Embed(media)
+// Channel posts straight into the channel with this ID.
+type Channel string
+
+func (c Channel) post(s *discordgo.Session, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(string(c), &discordgo.MessageSend{
+		Embed:      embed,
+		Components: components,
+	})
+}
+
+func (c Channel) guild(s *discordgo.Session) string {
+	return channelGuild(s, string(c))
+}
+
+// Followup answers an interaction that has already been deferred.
+type Followup struct {
+	*discordgo.Interaction
+}
+
+func (f Followup) post(s *discordgo.Session, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) (*discordgo.Message, error) {
+	return s.FollowupMessageCreate(f.Interaction, true, &discordgo.WebhookParams{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+}
+
+func (f Followup) guild(s *discordgo.Session) string {
+	return f.GuildID
+}
+
+// mediaEmbed is the embed for media in a guild with guildSettings, or errAdult if it isn't allowed there.
+func mediaEmbed(media anilist.Media, guildSettings guildSettings) (discordgo.MessageEmbed, error) {
+	if media.IsAdult && !guildSettings.Adult {
+		return discordgo.MessageEmbed{}, errAdult
+	}
+	if guildSettings.Compact {
+		return CompactEmbed(media)
+	}
+	return Embed(media, guildSettings.staffGroups())
+}
+
+// Send an Embed message to the given Destination using the provided Session.
+func Send(s *discordgo.Session, dest Destination, media anilist.Media) (err error) {
+	guildSettings := settings.get(dest.guild(s))
+	embed, err := mediaEmbed(media, guildSettings)
+	if err != nil {
+		return
+	}
+
+	followUps := FollowUps(media, guildSettings.staffGroups())
+	sent, err := dest.post(s, &embed, Components(followUps))
+	if err != nil {
+		return
+	}
+
+	buttons.register(sent, followUps)
+	return
+}
+
+// SendEpisode lets a channel know a new episode of media has aired.
+func SendEpisode(s *discordgo.Session, dest Destination, schedule anilist.AiringSchedule, media anilist.Media) (err error) {
+	guildSettings := settings.get(dest.guild(s))
+	embed, err := mediaEmbed(media, guildSettings)
+	if err != nil {
+		return
+	}
+	embed.Title = fmt.Sprintf("%s episode %d just aired", embed.Title, schedule.Episode)
+
+	followUps := FollowUps(media, guildSettings.staffGroups())
+	sent, err := dest.post(s, &embed, Components(followUps))
 	if err != nil {
 		return
 	}
 
-	sent, err := s.ChannelMessageSendEmbed(channel, &embed)
+	buttons.register(sent, followUps)
+	return
+}
+
+// SendCharacter sends a character Embed message to the given Destination using the provided Session.
+func SendCharacter(s *discordgo.Session, dest Destination, character anilist.Character) (err error) {
+	embed, err := CharacterEmbed(character)
 	if err != nil {
 		return
 	}
 
-	creator, err := media.Creator()
-	if err == nil {
-		creatorButton := discordbuttons.Button{
-			Data:     nil,
-			Reaction: CreatorReaction,
-			Callback: func(s *discordgo.Session, r *discordgo.MessageReactionAdd, mID string, cID string, data interface{}) {
-				fmt.Println(creator.ID)
-				ctx := context.Background()
-				creatorMedia, err := anilist.MediaFromPersonID(ctx, creator.ID, 3)
-				if err != nil {
-					fmt.Println(err)
-					return
-				}
-
-				for _, media := range creatorMedia {
-					go Send(s, r.ChannelID, media)
-				}
-			},
-		}
-
-		remove, err := discordbuttons.AttachButton(s, sent.ID, sent.ChannelID, creatorButton, true)
-		if err == nil {
-			go callAfterN(remove, expirationDelay)
-			s.MessageReactionAdd(sent.ChannelID, sent.ID, creatorButton.Reaction)
-		}
+	_, err = dest.post(s, &embed, nil)
+	return
+}
+
+// SendStaff sends a staff profile Embed message to the given Destination using the provided Session.
+func SendStaff(s *discordgo.Session, dest Destination, staff anilist.Staff) (err error) {
+	embed, err := StaffEmbed(staff)
+	if err != nil {
+		return
 	}
 
-	director, err := media.Director()
-	if err == nil {
-		directorButton := discordbuttons.Button{
-			Data:     nil,
-			Reaction: DirectorReaction,
-			Callback: func(s *discordgo.Session, r *discordgo.MessageReactionAdd, mID string, cID string, data interface{}) {
-				fmt.Println(director.ID)
-				ctx := context.Background()
-				directorMedia, err := anilist.MediaFromPersonID(ctx, director.ID, 3)
-				if err != nil {
-					fmt.Println(err)
-					return
-				}
-
-				for _, media := range directorMedia {
-					go Send(s, r.ChannelID, media)
-				}
-			},
-		}
-		remove, err := discordbuttons.AttachButton(s, sent.ID, sent.ChannelID, directorButton, true)
-		if err == nil {
-			go callAfterN(remove, expirationDelay)
-			s.MessageReactionAdd(sent.ChannelID, sent.ID, directorButton.Reaction)
-		}
+	_, err = dest.post(s, &embed, nil)
+	return
+}
+
+// SendCast sends the cast of the media with id, with a button for each voice actor.
+func SendCast(s *discordgo.Session, dest Destination, id int) (err error) {
+	media, cast, err := ani.CastFromMediaID(context.Background(), id, 0)
+	if err != nil {
+		return
 	}
-	for i := range media.Studios.Edges {
-		// If we close over i itself, every callback will be the same, since i is updated in place by the for loop.
-		// Be creating a new, locally scoped variable and using that instead, our closure works correctly.
-		j := i
-		studioButton := discordbuttons.Button{
-			Data:     nil,
-			Reaction: StudioReactions[i%len(StudioReactions)],
-			Callback: func(s *discordgo.Session, r *discordgo.MessageReactionAdd, mID string, cID string, data interface{}) {
-				fmt.Println(media.Studios.Edges[j].Studio.ID)
-				ctx := context.Background()
-				studioMedia, err := anilist.MediaFromStudioID(ctx, media.Studios.Edges[j].Studio.ID, 3)
-				if err != nil {
-					fmt.Println(err)
-					return
-				}
-
-				for _, media := range studioMedia {
-					go Send(s, r.ChannelID, media)
-				}
-			},
-		}
-		remove, err := discordbuttons.AttachButton(s, sent.ID, sent.ChannelID, studioButton, true)
-		if err == nil {
-			go callAfterN(remove, expirationDelay)
-			s.MessageReactionAdd(sent.ChannelID, sent.ID, studioButton.Reaction)
-		}
+	if media.IsAdult && !settings.get(dest.guild(s)).Adult {
+		return errAdult
 	}
+	embed, err := CastEmbed(media, cast)
+	if err != nil {
+		return
+	}
+
+	followUps := CastFollowUps(cast)
+	sent, err := dest.post(s, &embed, Components(followUps))
+	if err != nil {
+		return
+	}
+
+	buttons.register(sent, followUps)
+	return
+}
+
+// SendStudio sends a studio profile Embed message, showing the page of productions it was looked up with.
+func SendStudio(s *discordgo.Session, dest Destination, studio anilist.StudioDetail) (err error) {
+	embed, err := StudioEmbed(studio)
+	if err != nil {
+		return
+	}
+
+	pageInfo := studio.Media.PageInfo
+	_, err = dest.post(s, &embed, pageControls("studio", strconv.Itoa(studio.ID), pageInfo.CurrentPage, pageInfo.LastPage))
 	return
 }
@@ -2,41 +2,65 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/buckley-w-david/anibot/pkg/anilist"
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	// Discord rejects embeds whose description is longer than this.
+	maxDescriptionLength = 4096
+	// Or that have more fields than this, or a field whose name or value is longer than these.
+	maxFields           = 25
+	maxFieldNameLength  = 256
+	maxFieldValueLength = 1024
+	// Or whose text, all told, is longer than this.
+	maxEmbedLength = 6000
+	// Compact embeds cut descriptions down to a blurb.
+	compactDescriptionLength = 300
+)
+
+// The most people listed for each group of key staff, so a big production team doesn't crowd out everything else.
+const maxKeyStaff = 3
+
 var (
-	MissingToken     string
-	DirectorReaction string
-	CreatorReaction  string
-	StudioReactions  []string
+	MissingToken    string
+	RoleEmojis      map[anilist.RoleGroup]string
+	CastEmoji       string
+	VoiceActorEmoji string
+	StudioEmojis    []string
 )
 
 func init() {
 	MissingToken = "No token provided. Please run: anibot -t <bot token>"
 
-	DirectorReaction = "👉"
-	CreatorReaction = "👈"
-	StudioReactions = []string{"1⃣", "2⃣", "3⃣", "4⃣", "5⃣", "6⃣", "7⃣", "8⃣", "9⃣", "🔟"}
+	RoleEmojis = map[anilist.RoleGroup]string{
+		anilist.RoleGroupDirection:    "🎬",
+		anilist.RoleGroupWriting:      "✍",
+		anilist.RoleGroupMusic:        "🎵",
+		anilist.RoleGroupArt:          "🎨",
+		anilist.RoleGroupOriginalWork: "📖",
+	}
+	CastEmoji = "🎭"
+	VoiceActorEmoji = "🎙"
+	StudioEmojis = []string{"1⃣", "2⃣", "3⃣", "4⃣", "5⃣", "6⃣", "7⃣", "8⃣", "9⃣", "🔟"}
 }
 
-// Embed transforms an anilist.MediaResposne struct into a discordgo.MessageEmbed.
-func Embed(media anilist.Media) (discordgo.MessageEmbed, error) {
+// Embed transforms an anilist.Media into a discordgo.MessageEmbed, listing the key staff in each of groups.
+func Embed(media anilist.Media, groups []anilist.RoleGroup) (discordgo.MessageEmbed, error) {
 	coverImage := discordgo.MessageEmbedThumbnail{
 		URL: media.CoverImage.Medium,
 	}
 
-	mediaType := discordgo.MessageEmbedField{
-		Name:   "Media Type",
-		Value:  fmt.Sprintf("%s %s %s", media.MediaType, media.Format, media.Source),
-		Inline: false,
+	fields := []*discordgo.MessageEmbedField{mediaTypeField(media)}
+	if next := nextEpisodeField(media); next != nil {
+		fields = append(fields, next)
 	}
-	fields := []*discordgo.MessageEmbedField{&mediaType}
 
-	// TODO: Account for more than 2 studios w.r.t reactions
 	var inline func(int) bool
 	if len(media.Studios.Edges)&1 == 0 {
 		inline = func(int) bool { return true }
@@ -47,7 +71,7 @@ func Embed(media anilist.Media) (discordgo.MessageEmbed, error) {
 	}
 	studios := make([]*discordgo.MessageEmbedField, len(media.Studios.Edges))
 	for i, studio := range media.Studios.Edges {
-		value := fmt.Sprintf("[%s](%s)", studio.Studio.Name, studio.Studio.SiteURL)
+		value := fmt.Sprintf("[%s](%s)", studio.Node.Name, studio.Node.SiteURL)
 		studios[i] = &discordgo.MessageEmbedField{
 			Name:   "Studio",
 			Value:  value,
@@ -56,35 +80,489 @@ func Embed(media anilist.Media) (discordgo.MessageEmbed, error) {
 	}
 	fields = append(fields, studios...)
 
-	director, err := media.Director()
-	if err == nil {
-		value := fmt.Sprintf("[%s %s](%s)", director.Name.First, director.Name.Last, director.SiteURL)
-		director := discordgo.MessageEmbedField{
-			Name:   "Director " + DirectorReaction,
-			Value:  value,
-			Inline: true,
+	for _, group := range groups {
+		credits := keyStaff(media, group)
+		if len(credits) == 0 {
+			continue
+		}
+		lines := make([]string, 0, len(credits))
+		for _, credit := range credits {
+			lines = append(lines, fmt.Sprintf("[%s](%s) · %s", staffName(credit.Staff), credit.Staff.SiteURL, credit.BaseRole))
 		}
-		fields = append(fields, &director)
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%s %s", group, RoleEmojis[group]),
+			Value:  listValue(lines),
+			Inline: true,
+		})
 	}
 
-	creator, err := media.Creator()
-	if err == nil {
-		value := fmt.Sprintf("[%s %s](%s)", creator.Name.First, creator.Name.Last, creator.SiteURL)
+	embed := discordgo.MessageEmbed{
+		URL:         media.SiteURL,
+		Title:       media.Title.Romaji,
+		Description: truncate(strings.Replace(media.Description, "<br>", "\n", -1), maxDescriptionLength),
+		Color:       0x00ff00,
+		Thumbnail:   &coverImage,
+		Fields:      fields,
+	}
+	fitEmbed(&embed)
+	return embed, nil
+}
 
-		creator := discordgo.MessageEmbedField{
-			Name:   "Original Creator " + CreatorReaction,
-			Value:  value,
-			Inline: true,
-		}
-		fields = append(fields, &creator)
+// CompactEmbed is a shorter version of Embed, with just the first paragraph of the description and
+// none of the credits, for servers that would rather keep things brief.
+func CompactEmbed(media anilist.Media) (discordgo.MessageEmbed, error) {
+	coverImage := discordgo.MessageEmbedThumbnail{
+		URL: media.CoverImage.Medium,
 	}
 
+	fields := []*discordgo.MessageEmbedField{mediaTypeField(media)}
+	if next := nextEpisodeField(media); next != nil {
+		fields = append(fields, next)
+	}
+
+	description := strings.Replace(media.Description, "<br>", "\n", -1)
+	description = strings.TrimSpace(strings.SplitN(strings.TrimSpace(description), "\n", 2)[0])
+
 	return discordgo.MessageEmbed{
 		URL:         media.SiteURL,
 		Title:       media.Title.Romaji,
-		Description: strings.Replace(media.Description, "<br>", "\n", -1),
+		Description: truncate(description, compactDescriptionLength),
 		Color:       0x00ff00,
 		Thumbnail:   &coverImage,
 		Fields:      fields,
 	}, nil
 }
+
+// keyStaff is who to list for group on media's embed: whoever holds its most senior role, up to maxKeyStaff of them.
+func keyStaff(media anilist.Media, group anilist.RoleGroup) []anilist.Credit {
+	credits := media.Leads(group)
+	if len(credits) > maxKeyStaff {
+		credits = credits[:maxKeyStaff]
+	}
+	return credits
+}
+
+// staffName is how staff is credited on embeds and buttons.
+func staffName(staff anilist.Staff) string {
+	if staff.Name.Full != "" {
+		return staff.Name.Full
+	}
+	return strings.TrimSpace(staff.Name.First + " " + staff.Name.Last)
+}
+
+func mediaTypeField(media anilist.Media) *discordgo.MessageEmbedField {
+	return &discordgo.MessageEmbedField{
+		Name:   "Media Type",
+		Value:  fmt.Sprintf("%s %s %s", media.Type, media.Format, media.Source),
+		Inline: false,
+	}
+}
+
+// nextEpisodeField counts down to media's next episode, or is nil if it doesn't have one coming up.
+func nextEpisodeField(media anilist.Media) *discordgo.MessageEmbedField {
+	next := media.NextAiringEpisode
+	if next == nil || time.Until(next.Time()) <= 0 {
+		return nil
+	}
+
+	value := fmt.Sprintf("Episode %d airs in %s", next.Episode, countdown(time.Until(next.Time())))
+	if media.Episodes != 0 {
+		value = fmt.Sprintf("Episode %d of %d airs in %s", next.Episode, media.Episodes, countdown(time.Until(next.Time())))
+	}
+	return &discordgo.MessageEmbedField{
+		Name:   "Next Episode",
+		Value:  value,
+		Inline: false,
+	}
+}
+
+// CharacterEmbed transforms an anilist.Character into a discordgo.MessageEmbed.
+func CharacterEmbed(character anilist.Character) (discordgo.MessageEmbed, error) {
+	thumbnail := discordgo.MessageEmbedThumbnail{
+		URL: character.Image.Medium,
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	if character.Name.Native != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Native Name",
+			Value:  character.Name.Native,
+			Inline: true,
+		})
+	}
+	if len(character.Name.Alternative) > 0 && character.Name.Alternative[0] != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Also Known As",
+			Value:  truncate(strings.Join(character.Name.Alternative, ", "), maxFieldValueLength),
+			Inline: true,
+		})
+	}
+
+	var appearances []string
+	var voiceActors []string
+	seen := make(map[int]bool)
+	for _, appearance := range character.Media.Edges {
+		media := appearance.Node
+		appearances = append(appearances, fmt.Sprintf("[%s](%s) %s (%s)", media.Title.Romaji, media.SiteURL, media.Format, strings.Title(strings.ToLower(appearance.CharacterRole.String()))))
+		for _, va := range appearance.VoiceActors {
+			if seen[va.ID] {
+				continue
+			}
+			seen[va.ID] = true
+			voiceActors = append(voiceActors, fmt.Sprintf("[%s %s](%s)", va.Name.First, va.Name.Last, va.SiteURL))
+		}
+	}
+	if len(appearances) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Appears In",
+			Value:  listValue(appearances),
+			Inline: false,
+		})
+	}
+	if len(voiceActors) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Voiced By",
+			Value:  listValue(voiceActors),
+			Inline: false,
+		})
+	}
+
+	embed := discordgo.MessageEmbed{
+		URL:         character.SiteURL,
+		Title:       character.Name.Full,
+		Description: truncate(spoilers(strings.Replace(character.Description, "<br>", "\n", -1)), maxDescriptionLength),
+		Color:       0x00ff00,
+		Thumbnail:   &thumbnail,
+		Fields:      fields,
+	}
+	fitEmbed(&embed)
+	return embed, nil
+}
+
+// StaffEmbed transforms an anilist.Staff into a discordgo.MessageEmbed, listing their notable works as fields.
+func StaffEmbed(staff anilist.Staff) (discordgo.MessageEmbed, error) {
+	thumbnail := discordgo.MessageEmbedThumbnail{
+		URL: staff.Image.Medium,
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	if staff.Name.Native != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Native Name",
+			Value:  staff.Name.Native,
+			Inline: true,
+		})
+	}
+	if len(staff.PrimaryOccupations) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Occupations",
+			Value:  strings.Join(staff.PrimaryOccupations, ", "),
+			Inline: true,
+		})
+	}
+	if active := yearsActive(staff.YearsActive); active != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Years Active",
+			Value:  active,
+			Inline: true,
+		})
+	}
+
+	var voiceRoles []string
+	for _, role := range staff.CharacterMedia.Edges {
+		media := role.Node
+		for _, character := range role.Characters {
+			voiceRoles = append(voiceRoles, fmt.Sprintf("[%s](%s) in [%s](%s)", character.Name.Full, character.SiteURL, media.Title.Romaji, media.SiteURL))
+		}
+	}
+	if len(voiceRoles) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Voice Roles",
+			Value:  listValue(voiceRoles),
+			Inline: false,
+		})
+	}
+
+	for _, credit := range staff.StaffMedia.Edges {
+		media := credit.Node
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   truncate(media.Title.Romaji, maxFieldNameLength),
+			Value:  truncate(fmt.Sprintf("[%s](%s) %s", credit.StaffRole, media.SiteURL, media.Format), maxFieldValueLength),
+			Inline: true,
+		})
+	}
+
+	embed := discordgo.MessageEmbed{
+		URL:         staff.SiteURL,
+		Title:       staff.Name.Full,
+		Description: truncate(spoilers(strings.Replace(staff.Description, "<br>", "\n", -1)), maxDescriptionLength),
+		Color:       0x00ff00,
+		Thumbnail:   &thumbnail,
+		Fields:      fields,
+	}
+	// Someone with a long career has more credits than fit, so the last of them are left off.
+	fitEmbed(&embed)
+	return embed, nil
+}
+
+// CastEmbed lists media's main characters, each with their Japanese and English voice actors.
+func CastEmbed(media anilist.Media, cast []anilist.CastMember) (discordgo.MessageEmbed, error) {
+	lines := make([]string, 0, len(cast))
+	voiced := false
+	for _, member := range cast {
+		line := fmt.Sprintf("**[%s](%s)**", member.Character.Name.Full, member.Character.SiteURL)
+		for _, language := range []struct {
+			name        string
+			voiceActors []anilist.Staff
+		}{{"JP", member.Japanese}, {"EN", member.English}} {
+			if len(language.voiceActors) == 0 {
+				continue
+			}
+			voiced = true
+			names := make([]string, 0, len(language.voiceActors))
+			for _, va := range language.voiceActors {
+				names = append(names, fmt.Sprintf("[%s](%s)", staffName(va), va.SiteURL))
+			}
+			line += fmt.Sprintf(" · %s %s", language.name, strings.Join(names, ", "))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "AniList doesn't list any main characters for this yet.")
+	}
+
+	var footer *discordgo.MessageEmbedFooter
+	if voiced {
+		footer = &discordgo.MessageEmbedFooter{Text: "Press a voice actor's button to see their other roles"}
+	}
+
+	return discordgo.MessageEmbed{
+		URL:         media.SiteURL + "/characters",
+		Title:       "Cast of " + media.Title.Romaji,
+		Description: truncate(strings.Join(lines, "\n"), maxDescriptionLength),
+		Color:       0x00ff00,
+		Footer:      footer,
+	}, nil
+}
+
+// StudioEmbed transforms an anilist.StudioDetail into a discordgo.MessageEmbed, listing the page of
+// productions it was looked up with.
+func StudioEmbed(studio anilist.StudioDetail) (discordgo.MessageEmbed, error) {
+	kind := "Studio"
+	if studio.IsAnimationStudio {
+		kind = "Animation Studio"
+	}
+	pageInfo := studio.Media.PageInfo
+	lines := []string{fmt.Sprintf("%s · %d favourites · %d productions\n", kind, studio.Favourites, pageInfo.Total)}
+
+	for _, production := range studio.Media.Edges {
+		media := production.Node
+		year := "TBA"
+		if media.StartDate.Year != 0 {
+			year = strconv.Itoa(media.StartDate.Year)
+		}
+		role := "Production"
+		if production.IsMainStudio {
+			role = "Animation"
+		}
+		lines = append(lines, fmt.Sprintf("`%s` [%s](%s) %s · %s", year, media.Title.Romaji, media.SiteURL, media.Format, role))
+	}
+
+	var footer *discordgo.MessageEmbedFooter
+	if pageInfo.LastPage > 1 {
+		footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", pageInfo.CurrentPage, pageInfo.LastPage)}
+	}
+
+	return discordgo.MessageEmbed{
+		URL:         studio.SiteURL,
+		Title:       studio.Name,
+		Description: truncate(strings.Join(lines, "\n"), maxDescriptionLength),
+		Color:       0x00ff00,
+		Footer:      footer,
+	}, nil
+}
+
+// AiringEmbed lists one page of upcoming episodes. Times are rendered by Discord in each reader's own timezone,
+// with the date included when the listing spans more than a day.
+func AiringEmbed(heading string, schedules []anilist.AiringSchedule, withDate bool, page int, lastPage int) (discordgo.MessageEmbed, error) {
+	style := "t"
+	if withDate {
+		style = "f"
+	}
+
+	lines := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		media := schedule.Media
+		lines = append(lines, fmt.Sprintf("<t:%d:%s> [%s](%s) episode %d", schedule.AiringAt, style, media.Title.Romaji, media.SiteURL, schedule.Episode))
+	}
+	description := strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		description = "Nothing is scheduled to air."
+	}
+
+	var footer *discordgo.MessageEmbedFooter
+	if lastPage > 1 {
+		footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", page, lastPage)}
+	}
+
+	return discordgo.MessageEmbed{
+		Title:       heading,
+		Description: truncate(description, maxDescriptionLength),
+		Color:       0x00ff00,
+		Footer:      footer,
+	}, nil
+}
+
+// SearchEmbed lists one page of search results, numbered from first so they line up with the pick buttons.
+func SearchEmbed(heading string, medias []anilist.Media, first int, page int, lastPage int) (discordgo.MessageEmbed, error) {
+	lines := make([]string, 0, len(medias))
+	for i, media := range medias {
+		year := "TBA"
+		if media.StartDate.Year != 0 {
+			year = strconv.Itoa(media.StartDate.Year)
+		}
+		lines = append(lines, fmt.Sprintf("`%d.` [%s](%s) %s %s · %s", first+i, media.Title.Romaji, media.SiteURL, media.Type, media.Format, year))
+	}
+	description := strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		description = "Nothing matched."
+	}
+
+	footer := &discordgo.MessageEmbedFooter{Text: "Pick a number to see more"}
+	if lastPage > 1 {
+		footer.Text = fmt.Sprintf("Page %d of %d · %s", page, lastPage, footer.Text)
+	}
+
+	return discordgo.MessageEmbed{
+		Title:       heading,
+		Description: truncate(description, maxDescriptionLength),
+		Color:       0x00ff00,
+		Footer:      footer,
+	}, nil
+}
+
+// SeasonEmbed lists one page of a seasonal chart, numbered by popularity across the whole season.
+func SeasonEmbed(heading string, medias []anilist.Media, pageInfo anilist.PageInfo) (discordgo.MessageEmbed, error) {
+	first := (pageInfo.CurrentPage-1)*pageInfo.PerPage + 1
+
+	lines := make([]string, 0, len(medias))
+	for i, media := range medias {
+		details := []string{}
+		if media.Format != "" {
+			details = append(details, media.Format.String())
+		}
+		if len(media.Studios.Edges) > 0 {
+			details = append(details, media.Studios.Edges[0].Node.Name)
+		}
+		if media.AverageScore != 0 {
+			details = append(details, fmt.Sprintf("%d%%", media.AverageScore))
+		}
+		lines = append(lines, fmt.Sprintf("`#%d` [%s](%s) %s", first+i, media.Title.Romaji, media.SiteURL, strings.Join(details, " · ")))
+	}
+	description := strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		description = "Nothing is listed for this season yet."
+	}
+
+	var footer *discordgo.MessageEmbedFooter
+	if pageInfo.LastPage > 1 {
+		footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", pageInfo.CurrentPage, pageInfo.LastPage)}
+	}
+
+	return discordgo.MessageEmbed{
+		Title:       heading,
+		Description: truncate(description, maxDescriptionLength),
+		Color:       0x00ff00,
+		Footer:      footer,
+	}, nil
+}
+
+// countdown formats d to the two most significant units, e.g. "2d 4h" or "35m". A cached airing
+// time can already be past, which counts as 0m rather than a negative countdown.
+func countdown(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < 0 {
+		d = 0
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// yearsActive formats AniList's [start, end] yearsActive list, where a missing end means still active.
+func yearsActive(years []int) string {
+	switch len(years) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%d–present", years[0])
+	default:
+		return fmt.Sprintf("%d–%d", years[0], years[1])
+	}
+}
+
+// spoilers converts AniList's ~!spoiler!~ markup into Discord's ||spoiler||.
+func spoilers(text string) string {
+	return strings.NewReplacer("~!", "||", "!~", "||").Replace(text)
+}
+
+// truncate shortens text to at most max characters, marking that it was cut off.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	if max < 1 {
+		return ""
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// listValue puts lines one under the other in a field value. Lines that don't fit are left off whole,
+// rather than cutting a link in half, and the value says how many there were.
+func listValue(lines []string) string {
+	value := strings.Join(lines, "\n")
+	for kept := len(lines); utf8.RuneCountInString(value) > maxFieldValueLength && kept > 1; {
+		kept--
+		value = fmt.Sprintf("%s\n…and %d more", strings.Join(lines[:kept], "\n"), len(lines)-kept)
+	}
+	return truncate(value, maxFieldValueLength)
+}
+
+// embedLength is how much of embed's text counts towards maxEmbedLength.
+func embedLength(embed *discordgo.MessageEmbed) int {
+	length := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	if embed.Footer != nil {
+		length += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		length += utf8.RuneCountInString(embed.Author.Name)
+	}
+	return length
+}
+
+// fitEmbed brings embed within maxFields and maxEmbedLength by leaving off fields from the end,
+// and then, if that isn't enough, shortening the description.
+func fitEmbed(embed *discordgo.MessageEmbed) {
+	if len(embed.Fields) > maxFields {
+		embed.Fields = embed.Fields[:maxFields]
+	}
+	for len(embed.Fields) > 0 && embedLength(embed) > maxEmbedLength {
+		embed.Fields = embed.Fields[:len(embed.Fields)-1]
+	}
+	if over := embedLength(embed) - maxEmbedLength; over > 0 {
+		embed.Description = truncate(embed.Description, utf8.RuneCountInString(embed.Description)-over)
+	}
+}
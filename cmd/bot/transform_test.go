@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist"
+	"github.com/bwmarrin/discordgo"
+)
+
+// checkLimits fails t if Discord would reject embed.
+func checkLimits(t *testing.T, embed discordgo.MessageEmbed) {
+	t.Helper()
+	if len(embed.Fields) > maxFields {
+		t.Errorf("%d fields, more than %d", len(embed.Fields), maxFields)
+	}
+	if n := utf8.RuneCountInString(embed.Description); n > maxDescriptionLength {
+		t.Errorf("description is %d long, more than %d", n, maxDescriptionLength)
+	}
+	for _, field := range embed.Fields {
+		if n := utf8.RuneCountInString(field.Name); n > maxFieldNameLength {
+			t.Errorf("field name is %d long, more than %d", n, maxFieldNameLength)
+		}
+		if n := utf8.RuneCountInString(field.Value); n > maxFieldValueLength {
+			t.Errorf("%s is %d long, more than %d", field.Name, n, maxFieldValueLength)
+		}
+	}
+	if n := embedLength(&embed); n > maxEmbedLength {
+		t.Errorf("embed is %d long, more than %d", n, maxEmbedLength)
+	}
+}
+
+// prolific is a staff member with far more credits and voice roles than an embed can hold.
+func prolific() anilist.Staff {
+	var staff anilist.Staff
+	staff.Name.Full = "Prolific Person"
+	staff.Description = strings.Repeat("A long career. ", 500)
+	for i := 0; i < 100; i++ {
+		var edge anilist.MediaEdge
+		edge.Node.Title.Romaji = fmt.Sprintf("Show %d with a fairly long title to take up room", i)
+		edge.Node.SiteURL = fmt.Sprintf("https://anilist.co/anime/%d", i)
+		edge.StaffRole = "Key Animation (eps 1, 3, 5, 7, 9, 11)"
+		staff.StaffMedia.Edges = append(staff.StaffMedia.Edges, edge)
+
+		var character anilist.Character
+		character.Name.Full = fmt.Sprintf("Character %d", i)
+		character.SiteURL = fmt.Sprintf("https://anilist.co/character/%d", i)
+		edge.Characters = []anilist.Character{character}
+		staff.CharacterMedia.Edges = append(staff.CharacterMedia.Edges, edge)
+	}
+	return staff
+}
+
+func TestStaffEmbedLimits(t *testing.T) {
+	embed, err := StaffEmbed(prolific())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLimits(t, embed)
+
+	var roles *discordgo.MessageEmbedField
+	for _, field := range embed.Fields {
+		if field.Name == "Voice Roles" {
+			roles = field
+		}
+	}
+	if roles == nil {
+		t.Fatal("no Voice Roles field")
+	}
+	if !strings.HasSuffix(roles.Value, " more") || !strings.HasSuffix(strings.Split(roles.Value, "\n")[0], ")") {
+		t.Errorf("Voice Roles should list whole lines and say how many are left off, got %q", roles.Value)
+	}
+}
+
+func TestStaffEmbedSmall(t *testing.T) {
+	var staff anilist.Staff
+	staff.Name.Full = "Shinichiro Watanabe"
+	staff.Description = "Director."
+	var edge anilist.MediaEdge
+	edge.Node.Title.Romaji = "Cowboy Bebop"
+	edge.StaffRole = "Director"
+	staff.StaffMedia.Edges = []anilist.MediaEdge{edge}
+
+	embed, err := StaffEmbed(staff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embed.Description != "Director." || len(embed.Fields) != 1 || embed.Fields[0].Name != "Cowboy Bebop" {
+		t.Errorf("nothing should be trimmed from a small embed, got %+v", embed)
+	}
+}
+
+func TestListValue(t *testing.T) {
+	if got := listValue([]string{"a", "b"}); got != "a\nb" {
+		t.Errorf("got %q", got)
+	}
+
+	lines := make([]string, 200)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %03d", i)
+	}
+	got := listValue(lines)
+	if utf8.RuneCountInString(got) > maxFieldValueLength {
+		t.Errorf("%d long", utf8.RuneCountInString(got))
+	}
+	kept := strings.Count(got, "line ")
+	if want := fmt.Sprintf("…and %d more", len(lines)-kept); !strings.HasSuffix(got, want) {
+		t.Errorf("should end %q, got %q", want, got[len(got)-30:])
+	}
+
+	if got := listValue([]string{strings.Repeat("x", 2000)}); utf8.RuneCountInString(got) != maxFieldValueLength {
+		t.Errorf("a single long line should be truncated, got %d", utf8.RuneCountInString(got))
+	}
+}
+
+func TestFitEmbed(t *testing.T) {
+	embed := discordgo.MessageEmbed{
+		Title:       "Title",
+		Description: strings.Repeat("d", maxDescriptionLength),
+		Footer:      &discordgo.MessageEmbedFooter{Text: "footer"},
+	}
+	for i := 0; i < 30; i++ {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: fmt.Sprint(i), Value: strings.Repeat("v", 100)})
+	}
+	fitEmbed(&embed)
+	checkLimits(t, embed)
+	if embed.Fields[0].Name != "0" || utf8.RuneCountInString(embed.Description) != maxDescriptionLength {
+		t.Errorf("the last fields should be dropped first")
+	}
+
+	embed = discordgo.MessageEmbed{
+		Title:       strings.Repeat("t", 256),
+		Description: strings.Repeat("d", maxDescriptionLength),
+		Author:      &discordgo.MessageEmbedAuthor{Name: strings.Repeat("a", 256)},
+		Footer:      &discordgo.MessageEmbedFooter{Text: strings.Repeat("f", 2048)},
+	}
+	fitEmbed(&embed)
+	if n := embedLength(&embed); n != maxEmbedLength {
+		t.Errorf("description should be shortened to fit exactly, got %d", n)
+	}
+}
+
+func TestEmbedLimits(t *testing.T) {
+	var media anilist.Media
+	media.Title.Romaji = "A Show Made By Everyone"
+	media.Description = strings.Repeat("A long synopsis.<br>", 500)
+	for i := 0; i < 40; i++ {
+		var studio anilist.StudioEdge
+		studio.Node.Name = fmt.Sprintf("Studio %d", i)
+		studio.Node.SiteURL = fmt.Sprintf("https://anilist.co/studio/%d", i)
+		media.Studios.Edges = append(media.Studios.Edges, studio)
+	}
+
+	embed, err := Embed(media, anilist.RoleGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLimits(t, embed)
+	if strings.Contains(embed.Description, "<br>") {
+		t.Error("line breaks should be turned into newlines")
+	}
+}
+
+func TestCharacterEmbedLimits(t *testing.T) {
+	var character anilist.Character
+	character.Name.Full = "Popular Character"
+	character.Name.Native = "人気者"
+	character.Description = strings.Repeat("~!A spoiler.!~ ", 500)
+	for i := 0; i < 100; i++ {
+		character.Name.Alternative = append(character.Name.Alternative, fmt.Sprintf("Nickname %d", i))
+
+		var edge anilist.MediaEdge
+		edge.Node.Title.Romaji = fmt.Sprintf("Show %d with a fairly long title to take up room", i)
+		edge.Node.SiteURL = fmt.Sprintf("https://anilist.co/anime/%d", i)
+		edge.Node.Format = anilist.MediaFormatTV
+		edge.CharacterRole = anilist.CharacterRoleMain
+		var va anilist.Staff
+		va.ID = i
+		va.Name.First = "Voice"
+		va.Name.Last = fmt.Sprintf("Actor %d", i)
+		va.SiteURL = fmt.Sprintf("https://anilist.co/staff/%d", i)
+		edge.VoiceActors = []anilist.Staff{va}
+		character.Media.Edges = append(character.Media.Edges, edge)
+	}
+
+	embed, err := CharacterEmbed(character)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLimits(t, embed)
+	for _, field := range embed.Fields {
+		if field.Name == "Appears In" && !strings.HasSuffix(field.Value, " more") {
+			t.Errorf("Appears In should say how many are left off, got %q", field.Value)
+		}
+	}
+}
+
+// bigCast is a cast of n main characters, each with a Japanese and an English voice actor.
+// Every character is voiced by a different pair, except that the first two share theirs.
+func bigCast(n int) []anilist.CastMember {
+	cast := make([]anilist.CastMember, 0, n)
+	for i := 0; i < n; i++ {
+		var member anilist.CastMember
+		member.Character.Name.Full = fmt.Sprintf("Character %d with a rather long name", i)
+		member.Character.SiteURL = fmt.Sprintf("https://anilist.co/character/%d", i)
+		actor := i
+		if i == 1 {
+			actor = 0
+		}
+		var jp, en anilist.Staff
+		jp.ID = 2 * actor
+		jp.Name.Full = fmt.Sprintf("Seiyuu %d", actor)
+		jp.SiteURL = fmt.Sprintf("https://anilist.co/staff/%d", jp.ID)
+		en.ID = 2*actor + 1
+		en.Name.Full = fmt.Sprintf("Dub Actor %d", actor)
+		en.SiteURL = fmt.Sprintf("https://anilist.co/staff/%d", en.ID)
+		member.Japanese = []anilist.Staff{jp}
+		member.English = []anilist.Staff{en}
+		cast = append(cast, member)
+	}
+	return cast
+}
+
+func TestCastEmbedLimits(t *testing.T) {
+	var media anilist.Media
+	media.Title.Romaji = "A Show With Everyone In It"
+	media.SiteURL = "https://anilist.co/anime/1"
+
+	embed, err := CastEmbed(media, bigCast(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLimits(t, embed)
+	if embed.Footer == nil {
+		t.Error("a voiced cast should say how to see the voice actors' roles")
+	}
+
+	embed, err = CastEmbed(media, bigCast(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[Character 0 with a rather long name](https://anilist.co/character/0)", "JP [Seiyuu 0]", "EN [Dub Actor 0]"} {
+		if !strings.Contains(embed.Description, want) {
+			t.Errorf("description should contain %q, got %q", want, embed.Description)
+		}
+	}
+
+	embed, err = CastEmbed(media, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embed.Footer != nil || !strings.Contains(embed.Description, "doesn't list any main characters") {
+		t.Errorf("an empty cast should say so, got %+v", embed)
+	}
+}
+
+func TestCastFollowUps(t *testing.T) {
+	followUps := CastFollowUps(bigCast(3))
+	// The first two characters share voice actors, so they only get buttons once.
+	if len(followUps) != 4 {
+		t.Fatalf("expected a button for each of 4 voice actors, got %d", len(followUps))
+	}
+	seen := map[string]bool{}
+	for _, f := range followUps {
+		if f.Kind != "person" || seen[f.customID()] {
+			t.Errorf("unexpected button %+v", f)
+		}
+		seen[f.customID()] = true
+	}
+
+	followUps = CastFollowUps(bigCast(100))
+	if len(followUps) != buttonsPerRow*maxButtonRows {
+		t.Errorf("expected as many buttons as a message can hold, got %d", len(followUps))
+	}
+	if rows := Components(followUps); len(rows) != maxButtonRows {
+		t.Errorf("expected %d rows, got %d", maxButtonRows, len(rows))
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{29 * time.Second, "0m"},
+		{30 * time.Second, "1m"},
+		{35 * time.Minute, "35m"},
+		{59*time.Minute + 40*time.Second, "1h 0m"},
+		{time.Hour, "1h 0m"},
+		{4*time.Hour + 5*time.Minute, "4h 5m"},
+		{23*time.Hour + 59*time.Minute, "23h 59m"},
+		{24 * time.Hour, "1d 0h"},
+		{2*24*time.Hour + 4*time.Hour + 59*time.Minute, "2d 4h"},
+		{-10 * time.Minute, "0m"},
+	}
+	for _, test := range tests {
+		if got := countdown(test.d); got != test.want {
+			t.Errorf("countdown(%s) = %q, want %q", test.d, got, test.want)
+		}
+	}
+}
@@ -1,14 +1,5 @@
 module github.com/buckley-w-david/anibot
 
-require (
-	github.com/buckley-w-david/discordbuttons v0.0.0-20181111175518-02f9e693f478
-	github.com/bwmarrin/discordgo v0.19.0
-	github.com/davecgh/go-spew v1.1.1
-	github.com/machinebox/graphql v0.2.2
-	github.com/matryer/is v1.2.0 // indirect
-	github.com/pkg/errors v0.8.0 // indirect
-	golang.org/x/crypto v0.0.0-20181106171534-e4dc69e5b2fd // indirect
-	golang.org/x/text v0.3.0 // indirect
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
-	gopkg.in/yaml.v2 v2.2.1 // indirect
-)
+go 1.16
+
+require github.com/bwmarrin/discordgo v0.28.1
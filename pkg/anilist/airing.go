@@ -0,0 +1,116 @@
+package anilist
+
+import (
+	"context"
+	"time"
+)
+
+// Time is when the episode airs. AiringAt holds the same, in seconds since the Unix epoch.
+func (a AiringSchedule) Time() time.Time {
+	return time.Unix(int64(a.AiringAt), 0)
+}
+
+type AiringSchedulePageResponse = Query
+
+// Season returns the AniList season and year that t falls in.
+func Season(t time.Time) (MediaSeason, int) {
+	switch t.Month() {
+	case time.January, time.February, time.March:
+		return MediaSeasonWinter, t.Year()
+	case time.April, time.May, time.June:
+		return MediaSeasonSpring, t.Year()
+	case time.July, time.August, time.September:
+		return MediaSeasonSummer, t.Year()
+	default:
+		return MediaSeasonFall, t.Year()
+	}
+}
+
+const (
+	airingPerPage = 50
+	// Bounds how many requests a single schedule lookup can make. A week of broadcasts
+	// usually fits in three or four pages.
+	maxAiringPages = 10
+)
+
+var airingScheduleQuery *operation
+
+func init() {
+	airingScheduleQuery = newOperation("AiringSchedules", `
+      Page(page: $page, perPage: $max) {`+pageInfoSelection+`
+        airingSchedules(airingAt_greater: $from, airingAt_lesser: $to, mediaId_in: $media, sort: TIME) {
+          id
+          airingAt
+          episode
+          media {
+            id
+            siteUrl
+            type
+            format
+            episodes
+            season
+            seasonYear
+            isAdult
+            title {
+              english
+              romaji
+            }
+          }
+        }
+      }
+    `,
+		variable{"from", "Int!"},
+		variable{"to", "Int!"},
+		variable{"media", "[Int]"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+	)
+	airingScheduleQuery.ttl = airingCacheTTL
+}
+
+// AiringQuery selects the episodes airing strictly between From and To.
+type AiringQuery struct {
+	From time.Time
+	To   time.Time
+	// MediaIDs restricts the results to episodes of these media, if set.
+	MediaIDs []int
+}
+
+// AiringSchedulesFromAiringQuery lists every episode matching query, soonest first.
+func (c *Client) AiringSchedulesFromAiringQuery(ctx context.Context, query AiringQuery) ([]AiringSchedule, error) {
+	var schedules []AiringSchedule
+	for page := 1; page <= maxAiringPages; page++ {
+		vars := map[string]interface{}{
+			"from": query.From.Unix(),
+			"to":   query.To.Unix(),
+			"page": page,
+			"max":  airingPerPage,
+		}
+		if len(query.MediaIDs) > 0 {
+			vars["media"] = query.MediaIDs
+		}
+
+		var res AiringSchedulePageResponse
+		if err := c.runOperation(ctx, airingScheduleQuery, 0, vars, &res); err != nil {
+			return []AiringSchedule{}, err
+		}
+		schedules = append(schedules, res.Page.AiringSchedules...)
+		if !res.Page.PageInfo.HasNextPage {
+			break
+		}
+	}
+	return schedules, nil
+}
+
+// AiringSchedulesBetween lists every episode airing between from and to, soonest first.
+func (c *Client) AiringSchedulesBetween(ctx context.Context, from time.Time, to time.Time) ([]AiringSchedule, error) {
+	return c.AiringSchedulesFromAiringQuery(ctx, AiringQuery{From: from, To: to})
+}
+
+func AiringSchedulesFromAiringQuery(ctx context.Context, query AiringQuery) ([]AiringSchedule, error) {
+	return DefaultClient.AiringSchedulesFromAiringQuery(ctx, query)
+}
+
+func AiringSchedulesBetween(ctx context.Context, from time.Time, to time.Time) ([]AiringSchedule, error) {
+	return DefaultClient.AiringSchedulesBetween(ctx, from, to)
+}
@@ -0,0 +1,30 @@
+package anilist
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeason(t *testing.T) {
+	tests := []struct {
+		time   time.Time
+		season MediaSeason
+		year   int
+	}{
+		{time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), MediaSeasonWinter, 2021},
+		{time.Date(2021, time.March, 31, 23, 59, 59, 0, time.UTC), MediaSeasonWinter, 2021},
+		{time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC), MediaSeasonSpring, 2021},
+		{time.Date(2021, time.June, 30, 23, 59, 59, 0, time.UTC), MediaSeasonSpring, 2021},
+		{time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC), MediaSeasonSummer, 2021},
+		{time.Date(2021, time.September, 30, 23, 59, 59, 0, time.UTC), MediaSeasonSummer, 2021},
+		{time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC), MediaSeasonFall, 2021},
+		// December is still the fall of its own year, not the winter of the next.
+		{time.Date(2021, time.December, 31, 23, 59, 59, 0, time.UTC), MediaSeasonFall, 2021},
+	}
+	for _, test := range tests {
+		season, year := Season(test.time)
+		if season != test.season || year != test.year {
+			t.Errorf("Season(%s) = %s %d, want %s %d", test.time.Format("2006-01-02 15:04"), season, year, test.season, test.year)
+		}
+	}
+}
@@ -3,365 +3,478 @@ package anilist
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-
-	//"github.com/davecgh/go-spew/spew"
-	"github.com/machinebox/graphql"
 )
 
-type Person struct {
-	ID      int    `json:"id"`
-	SiteURL string `json:"siteUrl"`
-	Name    struct {
-		First string `json:"first"`
-		Last  string `json:"last"`
-	}
-}
-
-type Studio struct {
-	ID      int    `json:"id"`
-	Name    string `json:"name"`
-	SiteURL string `json:"siteUrl"`
-}
-
-type Title struct {
-	English string `json:"english,omitempty"`
-	Romaji  string `json:"romaji,omitempty"`
-}
+// Person is the name the Staff credited on Media used to go by, before the types here were generated from the schema.
+type Person = Staff
 
-type CoverImage struct {
-	ExtraLarge string `json:"extraLarge,omitempty"`
-	Large      string `json:"large,omitempty"`
-	Medium     string `json:"medium,omitempty"`
-}
-
-type Media struct {
-	SiteURL     string     `json:"siteUrl"`
-	Title       Title      `json:"title"`
-	Description string     `json:"description"`
-	CoverImage  CoverImage `json:"coverImage"`
-	MediaType   string     `json:"type"`
-	Format      string     `json:"format"`
-	Source      string     `json:"source"`
-	Studios     struct {
-		Edges []struct {
-			Studio Studio `json:"node"`
-		} `json:"edges"`
-	} `json:"studios"`
-	Staff struct {
-		Edges []struct {
-			Role   string `json:"role"`
-			Person Person `json:"node"`
-		} `json:"edges"`
-	} `json:"staff"`
-}
-
-type MediaResponse struct {
-	Media Media `json:"Media"`
-}
-
-type MediaPageResponse struct {
-	Page struct {
-		PageInfo struct {
-			Total int `json:"total"`
-		} `json:"pageInfo"`
-		Media []Media `json:"media"`
-	}
-}
+// Title is the old name for MediaTitle.
+type Title = MediaTitle
 
-type StaffMediaResponse struct {
-	Staff struct {
-		StaffMedia struct {
-			Nodes []struct {
-				ID int `json:"id"`
-			} `json:"nodes"`
-		} `json:"staffMedia"`
-	} `json:"Staff"`
-}
+// CoverImage is the old name for MediaCoverImage.
+type CoverImage = MediaCoverImage
 
-type StudioMediaResponse struct {
-	Studio struct {
-		Media struct {
-			Nodes []struct {
-				ID int `json:"id"`
-			} `json:"nodes"`
-		} `json:"media"`
-	} `json:"Studio"`
-}
+// The responses to each query are all decoded into the schema's Query type. These names are kept for callers
+// that used the hand written response types they replace.
+type (
+	MediaResponse       = Query
+	MediaPageResponse   = Query
+	StaffMediaResponse  = Query
+	StudioMediaResponse = Query
+	StaffPageResponse   = Query
+	StudioPageResponse  = Query
+)
 
+// Director is whoever leads media's direction, whether they're credited as Director, Chief Director or Series Director.
 func (media Media) Director() (Person, error) {
-	for i := range media.Staff.Edges {
-		if media.Staff.Edges[i].Role == "Director" {
-			return media.Staff.Edges[i].Person, nil
+	for _, credit := range media.ByGroup(RoleGroupDirection) {
+		if credit.rank <= directorRank {
+			return credit.Staff, nil
 		}
 	}
-	return Person{}, errors.New("Unable to find director")
+	return Person{}, fmt.Errorf("%w: no director credited", ErrNotFound)
 }
 
+// Creator is whoever is credited with media's original work, like its Original Creator or, for manga, Story & Art.
 func (media Media) Creator() (Person, error) {
-	for i := range media.Staff.Edges {
-		if media.Staff.Edges[i].Role == "Original Creator" {
-			return media.Staff.Edges[i].Person, nil
-		}
+	if leads := media.Leads(RoleGroupOriginalWork); len(leads) > 0 {
+		return leads[0].Staff, nil
 	}
-	return Person{}, errors.New("Unable to find creator")
+	return Person{}, fmt.Errorf("%w: no creator credited", ErrNotFound)
 }
 
 type MediaQuery struct {
-	Title      string
-	ID         int
-	Type       string
-	Sort       []string
+	Title string
+	ID    int
+	Type  MediaType
+	Sort  []MediaSort
+
+	// The fields below narrow the results down, and are ignored when left as their zero value.
+	// Lists match media with any of their entries, and ranges include both ends.
+
+	Genres         []string
+	ExcludedGenres []string
+	Tags           []string
+	Formats        []MediaFormat
+	Status         MediaStatus
+	// Season only applies to anime.
+	Season     MediaSeason
+	SeasonYear int
+	// StartDateFrom and StartDateTo bound when the media started. Unknown parts are filled in
+	// to cover as much as possible, so a year alone covers the whole year.
+	StartDateFrom FuzzyDate
+	StartDateTo   FuzzyDate
+	// MinScore and MaxScore bound the average score, out of 100.
+	MinScore      int
+	MaxScore      int
+	MinPopularity int
+	MaxPopularity int
+	// IsAdult restricts results to adult media when true, and excludes it when false.
+	IsAdult *bool
+	// CountryOfOrigin is an ISO 3166-1 alpha-2 country code, e.g. JP or KR.
+	CountryOfOrigin string
+	Source          MediaSource
+
+	// Fields picks which parts of each Media to fetch, all of them when left as 0.
+	Fields MediaFields
+
+	// Page is which page of results to fetch, starting from 1. 0 also means the first page.
+	Page    int
+	PerPage int
+	// Deprecated: MaxResults is the old name for PerPage, and is only used when PerPage isn't set.
 	MaxResults int
 }
 
 type PersonQuery struct {
-	Name       string
-	ID         int
-	Type       string
+	Name string
+	ID   int
+	Type MediaType
+	// Fields picks which parts of each Media MediaFromPersonQuery fetches, all of them when left as 0.
+	Fields MediaFields
+	// Page is which page of results to fetch, starting from 1. 0 also means the first page.
+	Page    int
+	PerPage int
+	// Deprecated: MaxResults is the old name for PerPage, and is only used when PerPage isn't set.
 	MaxResults int
 }
 
 type StudioQuery struct {
-	Name       string
-	ID         int
+	Name string
+	ID   int
+	// Fields picks which parts of each Media MediaFromStudioQuery fetches, all of them when left as 0.
+	Fields MediaFields
+	// Page is which page of results to fetch, starting from 1. 0 also means the first page.
+	Page    int
+	PerPage int
+	// Deprecated: MaxResults is the old name for PerPage, and is only used when PerPage isn't set.
 	MaxResults int
 }
 
 var (
-	client *graphql.Client
+	mediaIDQuery    *operation
+	mediaTitleQuery *operation
 
-	mediaIDQuery    string
-	mediaTitleQuery string
+	mediaPersonQuery *operation
 
-	mediaPersonQuery string
+	mediaStudioQuery *operation
 
-	mediaStudioQuery string
+	staffSearchQuery  *operation
+	studioSearchQuery *operation
 )
 
+// ANIME and MANGA predate the generated MediaType constants.
 const (
-	ANIME MediaType = 0
-	MANGA MediaType = 1
+	ANIME = MediaTypeAnime
+	MANGA = MediaTypeManga
 )
 
+// pageInfoSelection is the selection for a full PageInfo.
+const pageInfoSelection = `
+          pageInfo {
+            total
+            perPage
+            currentPage
+            lastPage
+            hasNextPage
+          }`
+
 func init() {
-	client = graphql.NewClient("https://graphql.anilist.co/")
-
-	media := `
-      siteUrl
-      title{
-        english
-        romaji
-      }
-      description(asHtml: false)
-      coverImage {
-        extraLarge
-        large
-        medium
-      }
-      type
-      format
-      source
-      studios {
-        edges {
-          node {
-            id
-            name
-            siteUrl
+	mediaIDQuery = newOperation("MediaByID", `Media(id: $id) { %s }`,
+		variable{"id", "Int!"},
+	)
+
+	mediaTitleQuery = newOperation("MediaSearch", `
+        Page(page: $page, perPage: $max) {`+pageInfoSelection+`
+          media(
+            search: $search, id: $id, type: $type, sort: $sort,
+            genre_in: $genres, genre_not_in: $excludedGenres, tag_in: $tags, format_in: $formats,
+            status: $status, season: $season, seasonYear: $seasonYear,
+            startDate_greater: $startAfter, startDate_lesser: $startBefore,
+            averageScore_greater: $scoreAbove, averageScore_lesser: $scoreBelow,
+            popularity_greater: $popularityAbove, popularity_lesser: $popularityBelow,
+            isAdult: $isAdult, countryOfOrigin: $country, source: $source
+          ) {
+            %s
           }
         }
-      }
-      staff {
-        edges {
-          role
-          node{
-            id
-            siteUrl
-            name{
-              first
-              last
+    `,
+		variable{"search", "String"},
+		variable{"id", "Int"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+		variable{"type", "MediaType"},
+		variable{"sort", "[MediaSort]"},
+		variable{"genres", "[String]"},
+		variable{"excludedGenres", "[String]"},
+		variable{"tags", "[String]"},
+		variable{"formats", "[MediaFormat]"},
+		variable{"status", "MediaStatus"},
+		variable{"season", "MediaSeason"},
+		variable{"seasonYear", "Int"},
+		variable{"startAfter", "FuzzyDateInt"},
+		variable{"startBefore", "FuzzyDateInt"},
+		variable{"scoreAbove", "Int"},
+		variable{"scoreBelow", "Int"},
+		variable{"popularityAbove", "Int"},
+		variable{"popularityBelow", "Int"},
+		variable{"isAdult", "Boolean"},
+		variable{"country", "CountryCode"},
+		variable{"source", "MediaSource"},
+	)
+
+	mediaPersonQuery = newOperation("StaffMedia", `
+        Staff(id: $id, search: $search) {
+          staffMedia(sort: POPULARITY_DESC, type: $type, page: $page, perPage: $max) {`+pageInfoSelection+`
+            nodes {
+              %s
             }
           }
         }
-      }
-	`
-
-	mediaIDQuery = fmt.Sprintf(`query ($id: Int!) { Media(id: $id) { %s } }`, media)
-
-	mediaTitleQuery = fmt.Sprintf(`
-      query ($search: String, $id: Int, $max: Int!, $type: MediaType, $sort: [MediaSort]) {
-        Page(page: 1, perPage: $max) {
-          pageInfo {
-            total
-          }
-          media(search: $search, id: $id, type: $type, sort: $sort) {
-            %s
+    `,
+		variable{"id", "Int"},
+		variable{"search", "String"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+		variable{"type", "MediaType"},
+	)
+
+	mediaStudioQuery = newOperation("StudioMedia", `
+        Studio(id: $id, search: $search) {
+          media(sort: POPULARITY_DESC, page: $page, perPage: $max) {`+pageInfoSelection+`
+            nodes {
+              %s
+            }
           }
         }
-      }
-    `, media)
-
-	mediaPersonQuery = `
-	  query ($id: Int, $search: String, $max: Int!, $type: MediaType) {
-        Staff(id: $id, search: $search) {
-          staffMedia(sort:POPULARITY_DESC, type: $type, page: 1, perPage: $max) {
-            nodes {
-              id
+    `,
+		variable{"id", "Int"},
+		variable{"search", "String"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+	)
+
+	staffSearchQuery = newOperation("StaffSearch", `
+        Page(page: $page, perPage: $max) {`+pageInfoSelection+`
+          staff(search: $search) {
+            id
+            siteUrl
+            name {
+              first
+              last
             }
-          } 
+          }
         }
-      }
-		`
-
-	mediaStudioQuery = `
-    query ($id: Int, $search: String, $max: Int!) {
-      Studio(id: $id, search: $search) {
-        media(sort:POPULARITY_DESC, page: 1, perPage: $max) {
-          nodes{
+    `,
+		variable{"search", "String"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+	)
+
+	studioSearchQuery = newOperation("StudioSearch", `
+        Page(page: $page, perPage: $max) {`+pageInfoSelection+`
+          studios(search: $search) {
             id
+            name
+            siteUrl
           }
         }
-      }
-    }
-    `
+    `,
+		variable{"search", "String"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+	)
+
+	mediaTitleQuery.ttl = searchCacheTTL
+	staffSearchQuery.ttl = searchCacheTTL
+	studioSearchQuery.ttl = searchCacheTTL
 }
 
-func MediaFromMediaID(ctx context.Context, id int) (Media, error) {
-	idMediaQuery := graphql.NewRequest(mediaIDQuery)
-	idMediaQuery.Var("id", id)
+func (c *Client) MediaFromMediaID(ctx context.Context, id int) (Media, error) {
+	vars := map[string]interface{}{"id": id}
 
 	var res MediaResponse
-	if err := client.Run(ctx, idMediaQuery, &res); err != nil {
+	if err := c.runOperation(ctx, mediaIDQuery, 0, vars, &res); err != nil {
 		return Media{}, err
 	}
 	return res.Media, nil
 }
 
-func MediaFromMediaQuery(ctx context.Context, query MediaQuery) ([]Media, error) {
-	mediaQuery := graphql.NewRequest(mediaTitleQuery)
-	mediaQuery.Var("max", query.MaxResults)
+func (c *Client) MediaFromMediaQuery(ctx context.Context, query MediaQuery) ([]Media, error) {
+	medias, _, err := c.MediaPageFromMediaQuery(ctx, query)
+	return medias, err
+}
+
+// MediaPageFromMediaQuery fetches the page of media matching query that query.Page asks for,
+// along with where it sits among the rest of the results.
+func (c *Client) MediaPageFromMediaQuery(ctx context.Context, query MediaQuery) ([]Media, PageInfo, error) {
+	vars := pageVars(map[string]interface{}{}, query.Page, perPage(query.PerPage, query.MaxResults))
 	if query.Title != "" {
-		mediaQuery.Var("search", query.Title)
+		vars["search"] = query.Title
 	} else if query.ID != 0 {
-		mediaQuery.Var("id", query.ID)
+		vars["id"] = query.ID
 	}
 	if query.Type != "" {
-		mediaQuery.Var("type", query.Type)
+		vars["type"] = query.Type
 	}
 	if len(query.Sort) > 0 {
-		mediaQuery.Var("sort", query.Sort)
+		vars["sort"] = query.Sort
 	}
+	query.filterVars(vars)
 
 	var res MediaPageResponse
-	if err := client.Run(ctx, mediaQuery, &res); err != nil {
-		return []Media{}, err
+	if err := c.runOperation(ctx, mediaTitleQuery, query.Fields, vars, &res); err != nil {
+		return []Media{}, PageInfo{}, err
 	}
-	return res.Page.Media, nil
+	return res.Page.Media, res.Page.PageInfo, nil
+}
+
+func (c *Client) MediaFromPersonQuery(ctx context.Context, query PersonQuery) (response []Media, err error) {
+	medias, _, err := c.MediaPageFromPersonQuery(ctx, query)
+	return medias, err
 }
 
-func MediaFromPersonQuery(ctx context.Context, query PersonQuery) (response []Media, err error) {
-	mediaQuery := graphql.NewRequest(mediaPersonQuery)
+// MediaPageFromPersonQuery fetches the page of media credited to the person matching query that
+// query.Page asks for, along with where it sits among the rest of their credits.
+func (c *Client) MediaPageFromPersonQuery(ctx context.Context, query PersonQuery) ([]Media, PageInfo, error) {
+	vars := pageVars(map[string]interface{}{}, query.Page, perPage(query.PerPage, query.MaxResults))
 	if query.Name != "" {
-		mediaQuery.Var("name", query.Name)
+		vars["search"] = query.Name
 	} else if query.ID != 0 {
-		mediaQuery.Var("id", query.ID)
+		vars["id"] = query.ID
 	} else {
-		return []Media{}, errors.New("Neither ID or Name set in PersonQuery")
+		return []Media{}, PageInfo{}, fmt.Errorf("%w: neither ID nor Name set in PersonQuery", ErrInvalidQuery)
 	}
-
 	if query.Type != "" {
-		mediaQuery.Var("type", query.Type)
+		vars["type"] = query.Type
 	}
-	mediaQuery.Var("max", query.MaxResults)
 
 	var res StaffMediaResponse
-	if err := client.Run(ctx, mediaQuery, &res); err != nil {
-		return []Media{}, err
+	if err := c.runOperation(ctx, mediaPersonQuery, query.Fields, vars, &res); err != nil {
+		return []Media{}, PageInfo{}, err
 	}
-	for i := 0; i < len(res.Staff.StaffMedia.Nodes); i++ {
-		media, err := MediaFromMediaID(ctx, res.Staff.StaffMedia.Nodes[i].ID)
-		if err == nil {
-			response = append(response, media)
-		}
-	}
-	return
+	return res.Staff.StaffMedia.Nodes, res.Staff.StaffMedia.PageInfo, nil
 }
 
-func MediaFromStudioQuery(ctx context.Context, query StudioQuery) (response []Media, err error) {
-	mediaQuery := graphql.NewRequest(mediaStudioQuery)
+func (c *Client) MediaFromStudioQuery(ctx context.Context, query StudioQuery) (response []Media, err error) {
+	medias, _, err := c.MediaPageFromStudioQuery(ctx, query)
+	return medias, err
+}
+
+// MediaPageFromStudioQuery fetches the page of media made by the studio matching query that
+// query.Page asks for, along with where it sits among the rest of its productions.
+func (c *Client) MediaPageFromStudioQuery(ctx context.Context, query StudioQuery) ([]Media, PageInfo, error) {
+	vars := pageVars(map[string]interface{}{}, query.Page, perPage(query.PerPage, query.MaxResults))
 	if query.Name != "" {
-		mediaQuery.Var("name", query.Name)
+		vars["search"] = query.Name
 	} else if query.ID != 0 {
-		mediaQuery.Var("id", query.ID)
+		vars["id"] = query.ID
 	} else {
-		return []Media{}, errors.New("Neither ID or Name set in PersonQuery")
+		return []Media{}, PageInfo{}, fmt.Errorf("%w: neither ID nor Name set in StudioQuery", ErrInvalidQuery)
 	}
-	mediaQuery.Var("max", query.MaxResults)
 
 	var res StudioMediaResponse
-	if err := client.Run(ctx, mediaQuery, &res); err != nil {
-		return []Media{}, err
+	if err := c.runOperation(ctx, mediaStudioQuery, query.Fields, vars, &res); err != nil {
+		return []Media{}, PageInfo{}, err
 	}
-	for i := 0; i < len(res.Studio.Media.Nodes); i++ {
-		media, err := MediaFromMediaID(ctx, res.Studio.Media.Nodes[i].ID)
-		if err == nil {
-			response = append(response, media)
-		}
+	return res.Studio.Media.Nodes, res.Studio.Media.PageInfo, nil
+}
+
+// PeopleFromName searches for staff members whose name matches name.
+func (c *Client) PeopleFromName(ctx context.Context, name string, maxResults int) ([]Person, error) {
+	people, _, err := c.PeopleFromPersonQuery(ctx, PersonQuery{Name: name, PerPage: maxResults})
+	return people, err
+}
+
+// PeopleFromPersonQuery searches for one page of staff members whose name matches query.Name.
+func (c *Client) PeopleFromPersonQuery(ctx context.Context, query PersonQuery) ([]Person, PageInfo, error) {
+	vars := pageVars(map[string]interface{}{"search": query.Name}, query.Page, perPage(query.PerPage, query.MaxResults))
+
+	var res StaffPageResponse
+	if err := c.runOperation(ctx, staffSearchQuery, 0, vars, &res); err != nil {
+		return []Person{}, PageInfo{}, err
 	}
-	return
+	return res.Page.Staff, res.Page.PageInfo, nil
+}
 
+// StudiosFromName searches for studios whose name matches name.
+func (c *Client) StudiosFromName(ctx context.Context, name string, maxResults int) ([]Studio, error) {
+	studios, _, err := c.StudiosFromStudioQuery(ctx, StudioQuery{Name: name, PerPage: maxResults})
+	return studios, err
 }
 
-type MediaType int
+// StudiosFromStudioQuery searches for one page of studios whose name matches query.Name.
+func (c *Client) StudiosFromStudioQuery(ctx context.Context, query StudioQuery) ([]Studio, PageInfo, error) {
+	vars := pageVars(map[string]interface{}{"search": query.Name}, query.Page, perPage(query.PerPage, query.MaxResults))
 
-func (t MediaType) String() string {
-	switch t {
-	case ANIME:
-		return "ANIME"
-	case MANGA:
-		return "MANGA"
-	default:
-		return ""
+	var res StudioPageResponse
+	if err := c.runOperation(ctx, studioSearchQuery, 0, vars, &res); err != nil {
+		return []Studio{}, PageInfo{}, err
 	}
+	return res.Page.Studios, res.Page.PageInfo, nil
 }
 
-func MediaFromTitle(ctx context.Context, title string, maxResults int) ([]Media, error) {
-	mediaQuery := MediaQuery{Title: title, MaxResults: maxResults}
-	return MediaFromMediaQuery(ctx, mediaQuery)
+func (c *Client) MediaFromTitle(ctx context.Context, title string, maxResults int) ([]Media, error) {
+	mediaQuery := MediaQuery{Title: title, PerPage: maxResults}
+	return c.MediaFromMediaQuery(ctx, mediaQuery)
 }
 
-func MediaFromPersonName(ctx context.Context, name string, maxResults int) ([]Media, error) {
-	personQuery := PersonQuery{Name: name, MaxResults: maxResults}
-	return MediaFromPersonQuery(ctx, personQuery)
+func (c *Client) MediaFromPersonName(ctx context.Context, name string, maxResults int) ([]Media, error) {
+	personQuery := PersonQuery{Name: name, PerPage: maxResults}
+	return c.MediaFromPersonQuery(ctx, personQuery)
 }
 
-func MediaFromPersonID(ctx context.Context, id int, maxResults int) ([]Media, error) {
-	personQuery := PersonQuery{ID: id, MaxResults: maxResults}
-	return MediaFromPersonQuery(ctx, personQuery)
+func (c *Client) MediaFromPersonID(ctx context.Context, id int, maxResults int) ([]Media, error) {
+	personQuery := PersonQuery{ID: id, PerPage: maxResults}
+	return c.MediaFromPersonQuery(ctx, personQuery)
 }
 
-func MediaFromStudioName(ctx context.Context, name string, maxResults int) ([]Media, error) {
-	studioQuery := StudioQuery{Name: name, MaxResults: maxResults}
-	return MediaFromStudioQuery(ctx, studioQuery)
+func (c *Client) MediaFromStudioName(ctx context.Context, name string, maxResults int) ([]Media, error) {
+	studioQuery := StudioQuery{Name: name, PerPage: maxResults}
+	return c.MediaFromStudioQuery(ctx, studioQuery)
 }
 
-func MediaFromStudioID(ctx context.Context, id int, maxResults int) ([]Media, error) {
-	studioQuery := StudioQuery{ID: id, MaxResults: maxResults}
-	return MediaFromStudioQuery(ctx, studioQuery)
+func (c *Client) MediaFromStudioID(ctx context.Context, id int, maxResults int) ([]Media, error) {
+	studioQuery := StudioQuery{ID: id, PerPage: maxResults}
+	return c.MediaFromStudioQuery(ctx, studioQuery)
 }
 
 // Execute is for specialized more specific queries that clients may want to perform that the library does not
 // explicitly support. Try not to use this if at all possible.
-func Execute(ctx context.Context, query string, vars map[string]interface{}) (map[string]*json.RawMessage, error) {
-	mediaQuery := graphql.NewRequest(query)
-	for k, v := range vars {
-		mediaQuery.Var(k, v)
-	}
-
+func (c *Client) Execute(ctx context.Context, query string, vars map[string]interface{}) (map[string]*json.RawMessage, error) {
 	var res map[string]*json.RawMessage
-	if err := client.Run(ctx, mediaQuery, &res); err != nil {
+	if err := c.run(ctx, query, vars, &res); err != nil {
 		return map[string]*json.RawMessage{}, err
 	}
 	return res, nil
 }
+
+// The functions below use DefaultClient and are kept for callers that don't need their own Client.
+
+func MediaFromMediaID(ctx context.Context, id int) (Media, error) {
+	return DefaultClient.MediaFromMediaID(ctx, id)
+}
+
+func MediaFromMediaQuery(ctx context.Context, query MediaQuery) ([]Media, error) {
+	return DefaultClient.MediaFromMediaQuery(ctx, query)
+}
+
+func MediaPageFromMediaQuery(ctx context.Context, query MediaQuery) ([]Media, PageInfo, error) {
+	return DefaultClient.MediaPageFromMediaQuery(ctx, query)
+}
+
+func MediaFromPersonQuery(ctx context.Context, query PersonQuery) ([]Media, error) {
+	return DefaultClient.MediaFromPersonQuery(ctx, query)
+}
+
+func MediaPageFromPersonQuery(ctx context.Context, query PersonQuery) ([]Media, PageInfo, error) {
+	return DefaultClient.MediaPageFromPersonQuery(ctx, query)
+}
+
+func MediaFromStudioQuery(ctx context.Context, query StudioQuery) ([]Media, error) {
+	return DefaultClient.MediaFromStudioQuery(ctx, query)
+}
+
+func MediaPageFromStudioQuery(ctx context.Context, query StudioQuery) ([]Media, PageInfo, error) {
+	return DefaultClient.MediaPageFromStudioQuery(ctx, query)
+}
+
+func MediaFromTitle(ctx context.Context, title string, maxResults int) ([]Media, error) {
+	return DefaultClient.MediaFromTitle(ctx, title, maxResults)
+}
+
+func MediaFromPersonName(ctx context.Context, name string, maxResults int) ([]Media, error) {
+	return DefaultClient.MediaFromPersonName(ctx, name, maxResults)
+}
+
+func MediaFromPersonID(ctx context.Context, id int, maxResults int) ([]Media, error) {
+	return DefaultClient.MediaFromPersonID(ctx, id, maxResults)
+}
+
+func MediaFromStudioName(ctx context.Context, name string, maxResults int) ([]Media, error) {
+	return DefaultClient.MediaFromStudioName(ctx, name, maxResults)
+}
+
+func MediaFromStudioID(ctx context.Context, id int, maxResults int) ([]Media, error) {
+	return DefaultClient.MediaFromStudioID(ctx, id, maxResults)
+}
+
+func PeopleFromName(ctx context.Context, name string, maxResults int) ([]Person, error) {
+	return DefaultClient.PeopleFromName(ctx, name, maxResults)
+}
+
+func PeopleFromPersonQuery(ctx context.Context, query PersonQuery) ([]Person, PageInfo, error) {
+	return DefaultClient.PeopleFromPersonQuery(ctx, query)
+}
+
+func StudiosFromName(ctx context.Context, name string, maxResults int) ([]Studio, error) {
+	return DefaultClient.StudiosFromName(ctx, name, maxResults)
+}
+
+func StudiosFromStudioQuery(ctx context.Context, query StudioQuery) ([]Studio, PageInfo, error) {
+	return DefaultClient.StudiosFromStudioQuery(ctx, query)
+}
+
+func Execute(ctx context.Context, query string, vars map[string]interface{}) (map[string]*json.RawMessage, error) {
+	return DefaultClient.Execute(ctx, query, vars)
+}
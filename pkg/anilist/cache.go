@@ -0,0 +1,242 @@
+package anilist
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores raw query responses, keyed by the query text and its variables.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, if it exists and has not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheStats counts how often lookups were answered from the Client's Cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// WithCache makes the Client answer repeated media lookups from cache, keeping each
+// response for ttl. Searches and airing schedules are kept for a few minutes at most.
+func WithCache(cache Cache, ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+// CacheStats reports the Client's cache hits and misses so far.
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.cacheHits),
+		Misses: atomic.LoadUint64(&c.cacheMisses),
+	}
+}
+
+// How long responses that go stale quickly are cached for at most, whatever the Client's TTL.
+const (
+	// Searches are re-ranked as media gains popularity, and find new entries as they're added.
+	searchCacheTTL = 10 * time.Minute
+	// Airing schedules shift whenever an episode is delayed.
+	airingCacheTTL = 5 * time.Minute
+)
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context that makes lookups made with it skip the Client's Cache,
+// for callers that need to see changes on AniList as soon as they happen.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheKey(query string, vars map[string]interface{}) string {
+	// encoding/json writes map keys in sorted order, so equal variables give equal keys.
+	encoded, _ := json.Marshal(vars)
+	sum := sha256.Sum256(append([]byte(query), encoded...))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry once full.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding at most capacity entries.
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.order.Remove(element)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expires = time.Now().Add(ttl)
+		m.order.MoveToFront(element)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// FileCache is a Cache that keeps one file per entry in a directory, so it survives restarts.
+// Expired entries are removed when the FileCache is created and every fileCacheSweepInterval writes after that.
+type FileCache struct {
+	dir    string
+	writes uint64
+}
+
+// How many Sets a FileCache makes between sweeps for expired entries.
+const fileCacheSweepInterval = 256
+
+// Temporary files older than this were left behind by a write that never finished.
+const staleTempFileAge = time.Hour
+
+type fileEntry struct {
+	Expires time.Time `json:"expires"`
+	Value   []byte    `json:"value"`
+}
+
+// NewFileCache creates a FileCache in dir, creating the directory if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f := &FileCache{dir: dir}
+	if _, err := f.Prune(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Prune removes the entries that have expired, any that can't be read, and temporary files left
+// behind by interrupted writes. It returns how many files it removed.
+func (f *FileCache) Prune() (int, error) {
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	for _, file := range files {
+		name := file.Name()
+		var stale bool
+		switch {
+		case file.IsDir():
+			continue
+		case strings.HasPrefix(name, "tmp-"):
+			stale = now.Sub(file.ModTime()) > staleTempFileAge
+		case strings.HasSuffix(name, ".json"):
+			data, err := ioutil.ReadFile(filepath.Join(f.dir, name))
+			if err != nil {
+				continue
+			}
+			var entry fileEntry
+			stale = json.Unmarshal(data, &entry) != nil || now.After(entry.Expires)
+		}
+		if stale && os.Remove(filepath.Join(f.dir, name)) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (f *FileCache) path(key string) string {
+	return filepath.Join(f.dir, key+".json")
+}
+
+func (f *FileCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(f.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry fileEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if time.Now().After(entry.Expires) {
+		os.Remove(f.path(key))
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	data, err := json.Marshal(fileEntry{Expires: time.Now().Add(ttl), Value: value})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first so a concurrent Get never sees a partial entry.
+	tmp, err := ioutil.TempFile(f.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	if atomic.AddUint64(&f.writes, 1)%fileCacheSweepInterval == 0 {
+		f.Prune()
+	}
+}
@@ -0,0 +1,93 @@
+package anilist
+
+import (
+	"context"
+)
+
+// CastMember is one of a Media's characters, along with who voices them.
+type CastMember struct {
+	Character Character     `json:"node"`
+	Role      CharacterRole `json:"role"`
+	Japanese  []Staff       `json:"japanese"`
+	English   []Staff       `json:"english"`
+}
+
+// MediaCastResponse is what the cast lookup decodes into. The voice actors for each language are
+// asked for separately under aliases, which the generated CharacterEdge has no fields for.
+type MediaCastResponse struct {
+	Media struct {
+		Media
+		// Shadows Media.Characters, so the edges decode as CastMembers instead.
+		Characters struct {
+			Edges []CastMember `json:"edges"`
+		} `json:"characters"`
+	} `json:"Media"`
+}
+
+// How many main characters are listed in a cast when the caller doesn't say.
+const defaultCastSize = 10
+
+var mediaCastQuery *operation
+
+func init() {
+	mediaCastQuery = newOperation("MediaCast", `
+      Media(id: $id) { %s
+        characters(role: MAIN, sort: [ROLE, RELEVANCE, ID], page: 1, perPage: $max) {
+          edges {
+            role
+            node {
+              id
+              siteUrl
+              name {
+                full
+                native
+              }
+              image {
+                medium
+              }
+            }
+            japanese: voiceActors(language: JAPANESE, sort: [RELEVANCE, ID]) {
+              id
+              siteUrl
+              languageV2
+              name {
+                full
+                native
+              }
+            }
+            english: voiceActors(language: ENGLISH, sort: [RELEVANCE, ID]) {
+              id
+              siteUrl
+              languageV2
+              name {
+                full
+                native
+              }
+            }
+          }
+        }
+      }
+    `,
+		variable{"id", "Int!"},
+		variable{"max", "Int!"},
+	)
+}
+
+// CastFromMediaID looks up the main characters of the media with id, and their Japanese and English
+// voice actors. Only the basic fields of the returned Media are filled in. maxResults defaults to 10.
+func (c *Client) CastFromMediaID(ctx context.Context, id int, maxResults int) (Media, []CastMember, error) {
+	if maxResults == 0 {
+		maxResults = defaultCastSize
+	}
+	vars := map[string]interface{}{"id": id, "max": maxResults}
+
+	var res MediaCastResponse
+	if err := c.runOperation(ctx, mediaCastQuery, MediaFieldBasic, vars, &res); err != nil {
+		return Media{}, []CastMember{}, err
+	}
+	return res.Media.Media, res.Media.Characters.Edges, nil
+}
+
+func CastFromMediaID(ctx context.Context, id int, maxResults int) (Media, []CastMember, error) {
+	return DefaultClient.CastFromMediaID(ctx, id, maxResults)
+}
@@ -0,0 +1,120 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+)
+
+// CharacterAppearance is a piece of media a character appears in, along with who voiced them in it.
+type CharacterAppearance = MediaEdge
+
+type CharacterPageResponse = Query
+
+type CharacterQuery struct {
+	Name       string
+	ID         int
+	MaxResults int
+	// Appearances is how many pieces of media to list for each character, most popular first.
+	Appearances int
+}
+
+const defaultAppearances = 5
+
+var characterQuery *operation
+
+func init() {
+	characterQuery = newOperation("Characters", `
+      Page(page: 1, perPage: $max) {
+        characters(id: $id, search: $search, sort: [SEARCH_MATCH, FAVOURITES_DESC]) {
+          id
+          siteUrl
+          name {
+            full
+            native
+            alternative
+          }
+          description(asHtml: false)
+          favourites
+          image {
+            large
+            medium
+          }
+          media(sort: POPULARITY_DESC, page: 1, perPage: $appearances) {
+            edges {
+              characterRole
+              voiceActors(language: JAPANESE) {
+                id
+                siteUrl
+                name {
+                  first
+                  last
+                }
+              }
+              node {
+                id
+                siteUrl
+                type
+                format
+                title {
+                  english
+                  romaji
+                }
+              }
+            }
+          }
+        }
+      }
+    `,
+		variable{"id", "Int"},
+		variable{"search", "String"},
+		variable{"max", "Int!"},
+		variable{"appearances", "Int!"},
+	)
+}
+
+func (c *Client) CharactersFromCharacterQuery(ctx context.Context, query CharacterQuery) ([]Character, error) {
+	vars := map[string]interface{}{"max": query.MaxResults, "appearances": query.Appearances}
+	if query.Name != "" {
+		vars["search"] = query.Name
+	} else if query.ID != 0 {
+		vars["id"] = query.ID
+	} else {
+		return []Character{}, fmt.Errorf("%w: neither ID nor Name set in CharacterQuery", ErrInvalidQuery)
+	}
+	if query.Appearances == 0 {
+		vars["appearances"] = defaultAppearances
+	}
+
+	var res CharacterPageResponse
+	if err := c.runOperation(ctx, characterQuery, 0, vars, &res); err != nil {
+		return []Character{}, err
+	}
+	return res.Page.Characters, nil
+}
+
+func (c *Client) CharacterFromCharacterID(ctx context.Context, id int) (Character, error) {
+	characters, err := c.CharactersFromCharacterQuery(ctx, CharacterQuery{ID: id, MaxResults: 1})
+	if err != nil {
+		return Character{}, err
+	}
+	if len(characters) == 0 {
+		return Character{}, fmt.Errorf("%w: no character with ID %d", ErrNotFound, id)
+	}
+	return characters[0], nil
+}
+
+func (c *Client) CharactersFromName(ctx context.Context, name string, maxResults int) ([]Character, error) {
+	return c.CharactersFromCharacterQuery(ctx, CharacterQuery{Name: name, MaxResults: maxResults})
+}
+
+func CharactersFromCharacterQuery(ctx context.Context, query CharacterQuery) ([]Character, error) {
+	return DefaultClient.CharactersFromCharacterQuery(ctx, query)
+}
+
+func CharacterFromCharacterID(ctx context.Context, id int) (Character, error) {
+	return DefaultClient.CharacterFromCharacterID(ctx, id)
+}
+
+func CharactersFromName(ctx context.Context, name string, maxResults int) ([]Character, error) {
+	return DefaultClient.CharactersFromName(ctx, name, maxResults)
+}
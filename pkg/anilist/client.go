@@ -0,0 +1,202 @@
+package anilist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEndpoint is the public AniList GraphQL API.
+const DefaultEndpoint = "https://graphql.anilist.co/"
+
+// DefaultClient is the Client used by the package level lookup functions.
+var DefaultClient = NewClient()
+
+// Client performs lookups against an AniList compatible GraphQL endpoint.
+// The zero value is not usable, create one with NewClient.
+type Client struct {
+	// Accessed atomically, kept first for 64-bit alignment on 32-bit platforms.
+	cacheHits   uint64
+	cacheMisses uint64
+
+	endpoint   string
+	httpClient *http.Client
+	userAgent  string
+	timeout    time.Duration
+
+	limiter  *rateLimiter
+	cache    Cache
+	cacheTTL time.Duration
+	// transport is httpClient wrapped in our rate limiting, which is what requests are actually sent with.
+	transport *http.Client
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithEndpoint points the Client at a GraphQL endpoint other than DefaultEndpoint.
+func WithEndpoint(endpoint string) ClientOption {
+	return func(c *Client) {
+		c.endpoint = endpoint
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout bounds how long a single request may take. A zero timeout means no limit
+// beyond whatever the context passed to each call imposes.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// NewClient creates a Client talking to DefaultEndpoint unless configured otherwise.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		endpoint:   DefaultEndpoint,
+		httpClient: http.DefaultClient,
+		limiter:    newRateLimiter(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Work on a copy so the caller's http.Client isn't affected by our rate limiting.
+	httpClient := *c.httpClient
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = &rateLimitTransport{base: base, limiter: c.limiter}
+	c.transport = &httpClient
+	return c
+}
+
+// Endpoint returns the GraphQL endpoint the Client sends requests to.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// graphQLResponse is the envelope every GraphQL response comes in.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+// run sends query to the endpoint and decodes the data it answers with into resp.
+// Whatever goes wrong is reported as one of the errors in errors.go.
+func (c *Client) run(ctx context.Context, query string, vars map[string]interface{}, resp interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}{query, vars})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	// A bytes.Reader body lets the rate limiter replay the request if it's throttled.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	res, err := c.transport.Do(req)
+	if err != nil {
+		var rateLimited *RateLimitError
+		if errors.As(err, &rateLimited) {
+			return rateLimited
+		}
+		return &NetworkError{Err: err}
+	}
+	defer res.Body.Close()
+	raw, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return &NetworkError{Err: err}
+	}
+
+	var gr graphQLResponse
+	if err := json.Unmarshal(raw, &gr); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return &UpstreamError{Status: res.StatusCode}
+		}
+		return &UpstreamError{Status: http.StatusBadGateway, Errors: []GraphQLError{{Message: "decoding response: " + err.Error()}}}
+	}
+	if len(gr.Errors) > 0 || res.StatusCode != http.StatusOK {
+		status := res.StatusCode
+		// AniList sometimes answers 200 and only says what went wrong in the errors themselves.
+		if status == http.StatusOK && len(gr.Errors) > 0 && gr.Errors[0].Status != 0 {
+			status = gr.Errors[0].Status
+		}
+		return &UpstreamError{Status: status, Errors: gr.Errors}
+	}
+
+	if err := json.Unmarshal(gr.Data, resp); err != nil {
+		return &UpstreamError{Status: http.StatusBadGateway, Errors: []GraphQLError{{Message: "decoding response: " + err.Error()}}}
+	}
+	return nil
+}
+
+// runCached behaves like run, but answers from the Client's Cache when it can. New responses are
+// cached for the Client's TTL, or ttl if that's set and shorter.
+func (c *Client) runCached(ctx context.Context, query string, vars map[string]interface{}, ttl time.Duration, resp interface{}) error {
+	if c.cache == nil || ctx.Value(noCacheKey{}) != nil {
+		return c.run(ctx, query, vars, resp)
+	}
+
+	key := cacheKey(query, vars)
+	if cached, ok := c.cache.Get(key); ok {
+		if err := json.Unmarshal(cached, resp); err == nil {
+			atomic.AddUint64(&c.cacheHits, 1)
+			return nil
+		}
+	}
+	atomic.AddUint64(&c.cacheMisses, 1)
+
+	if err := c.run(ctx, query, vars, resp); err != nil {
+		return err
+	}
+	if ttl <= 0 || ttl > c.cacheTTL {
+		ttl = c.cacheTTL
+	}
+	if encoded, err := json.Marshal(resp); err == nil {
+		c.cache.Set(key, encoded, ttl)
+	}
+	return nil
+}
+
+// runOperation checks vars against op, then runs it with fields selected wherever it asks for Media.
+func (c *Client) runOperation(ctx context.Context, op *operation, fields MediaFields, vars map[string]interface{}, resp interface{}) error {
+	if err := op.check(vars); err != nil {
+		return err
+	}
+	return c.runCached(ctx, op.query(fields), vars, op.ttl, resp)
+}
@@ -0,0 +1,549 @@
+package anilist
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const bebop = `{"data":{"Media":{"id":1,"title":{"romaji":"Cowboy Bebop"}}}}`
+
+// standIn is a local stand-in for AniList that answers every request with handler, counting them.
+func standIn(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func reply(status int, body string, headers ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func TestWithEndpoint(t *testing.T) {
+	var userAgent string
+	srv, requests := standIn(t, func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(bebop))
+	})
+	c := NewClient(WithEndpoint(srv.URL), WithUserAgent("anibot-test"))
+
+	if c.Endpoint() != srv.URL {
+		t.Errorf("Endpoint() = %q, want %q", c.Endpoint(), srv.URL)
+	}
+	media, err := c.MediaFromMediaID(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.Title.Romaji != "Cowboy Bebop" || *requests != 1 {
+		t.Errorf("got %q after %d requests", media.Title.Romaji, *requests)
+	}
+	if userAgent != "anibot-test" {
+		t.Errorf("User-Agent = %q", userAgent)
+	}
+}
+
+// countingTransport counts the requests it passes on to http.DefaultTransport.
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	srv, _ := standIn(t, reply(http.StatusOK, bebop))
+	transport := &countingTransport{}
+	c := NewClient(WithEndpoint(srv.URL), WithHTTPClient(&http.Client{Transport: transport}))
+
+	if _, err := c.MediaFromMediaID(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected the request to go through the given http.Client, %d did", transport.requests)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	srv, _ := standIn(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(bebop))
+	})
+	start := time.Now()
+	_, err := NewClient(WithEndpoint(srv.URL), WithTimeout(20*time.Millisecond)).MediaFromMediaID(context.Background(), 1)
+	if err == nil {
+		t.Error("expected the lookup to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("the lookup took %s despite a 20ms timeout", elapsed)
+	}
+}
+
+func TestCachedLookups(t *testing.T) {
+	srv, requests := standIn(t, reply(http.StatusOK, bebop))
+	c := NewClient(WithEndpoint(srv.URL), WithCache(NewMemoryCache(10), time.Minute))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		media, err := c.MediaFromMediaID(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if media.Title.Romaji != "Cowboy Bebop" {
+			t.Errorf("lookup %d got %q", i, media.Title.Romaji)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("expected the second lookup to be cached, made %d requests", *requests)
+	}
+	if stats := c.CacheStats(); stats != (CacheStats{Hits: 1, Misses: 1}) {
+		t.Errorf("CacheStats() = %+v", stats)
+	}
+
+	if _, err := c.MediaFromMediaID(WithoutCache(ctx), 1); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 {
+		t.Errorf("expected WithoutCache to skip the cache, made %d requests", *requests)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request)
+		is      []error
+		isNot   []error
+		status  int
+	}{
+		{
+			name:    "not found",
+			handler: reply(http.StatusNotFound, `{"errors":[{"message":"Not Found.","status":404,"locations":[{"line":1,"column":2}]}],"data":{"Media":null}}`),
+			is:      []error{ErrNotFound, ErrUpstream},
+			isNot:   []error{ErrInvalidQuery, ErrNetwork, ErrRateLimited},
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "invalid query",
+			handler: reply(http.StatusBadRequest, `{"errors":[{"message":"Cannot query field \"titel\".","status":400}],"data":null}`),
+			is:      []error{ErrInvalidQuery, ErrUpstream},
+			isNot:   []error{ErrNotFound},
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "error status in a 200",
+			handler: reply(http.StatusOK, `{"errors":[{"message":"Not Found.","status":404}],"data":{"Media":null}}`),
+			is:      []error{ErrNotFound, ErrUpstream},
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "html error page",
+			handler: reply(http.StatusBadGateway, `<html><body>Bad Gateway</body></html>`),
+			is:      []error{ErrUpstream},
+			isNot:   []error{ErrNotFound, ErrInvalidQuery, ErrNetwork},
+			status:  http.StatusBadGateway,
+		},
+		{
+			name:    "undecodable data",
+			handler: reply(http.StatusOK, `{"data":{"Media":{"id":"one"}}}`),
+			is:      []error{ErrUpstream},
+			status:  http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range tests {
+		srv, _ := standIn(t, test.handler)
+		c := NewClient(WithEndpoint(srv.URL))
+		_, err := c.MediaFromMediaID(context.Background(), 1)
+
+		for _, target := range test.is {
+			if !errors.Is(err, target) {
+				t.Errorf("%s: %v is not %v", test.name, err, target)
+			}
+		}
+		for _, target := range test.isNot {
+			if errors.Is(err, target) {
+				t.Errorf("%s: %v shouldn't be %v", test.name, err, target)
+			}
+		}
+		var upstream *UpstreamError
+		if !errors.As(err, &upstream) {
+			t.Errorf("%s: %v isn't an *UpstreamError", test.name, err)
+		} else if upstream.Status != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, upstream.Status, test.status)
+		}
+	}
+}
+
+func TestGraphQLErrorLocations(t *testing.T) {
+	srv, _ := standIn(t, reply(http.StatusBadRequest, `{"errors":[{"message":"Unknown argument.","status":400,"locations":[{"line":3,"column":7}]}]}`))
+	_, err := NewClient(WithEndpoint(srv.URL)).MediaFromMediaID(context.Background(), 1)
+
+	var upstream *UpstreamError
+	if !errors.As(err, &upstream) || len(upstream.Errors) != 1 {
+		t.Fatalf("expected one GraphQL error, got %v", err)
+	}
+	if got := upstream.Errors[0].Locations; len(got) != 1 || got[0] != (Location{Line: 3, Column: 7}) {
+		t.Errorf("locations = %+v", got)
+	}
+	if want := "anilist: 400 Unknown argument. (at 3:7)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	// Grab a port that nothing is listening on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + listener.Addr().String()
+	listener.Close()
+
+	_, err = NewClient(WithEndpoint(endpoint)).MediaFromMediaID(context.Background(), 1)
+	var network *NetworkError
+	if !errors.Is(err, ErrNetwork) || !errors.As(err, &network) {
+		t.Errorf("connection refused: expected a *NetworkError, got %v", err)
+	}
+	if errors.Is(err, ErrUpstream) {
+		t.Errorf("connection refused: %v shouldn't be ErrUpstream", err)
+	}
+
+	srv, _ := standIn(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(bebop))
+	})
+	_, err = NewClient(WithEndpoint(srv.URL), WithTimeout(20*time.Millisecond)).MediaFromMediaID(context.Background(), 1)
+	if !errors.Is(err, ErrNetwork) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timeout: expected ErrNetwork wrapping context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestValidationErrorsAreNotSent(t *testing.T) {
+	srv, requests := standIn(t, reply(http.StatusOK, `{"data":{}}`))
+	c := NewClient(WithEndpoint(srv.URL))
+
+	_, err := c.MediaFromStudioQuery(context.Background(), StudioQuery{})
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	_, err = c.MediaFromPersonQuery(context.Background(), PersonQuery{})
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	if *requests != 0 {
+		t.Errorf("invalid queries made %d requests", *requests)
+	}
+}
+
+func TestRetriesThrottledRequests(t *testing.T) {
+	var attempts int32
+	srv, requests := standIn(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			reply(http.StatusTooManyRequests, `{"errors":[{"message":"Too Many Requests.","status":429}]}`, "Retry-After", "0")(w, r)
+			return
+		}
+		reply(http.StatusOK, bebop, "X-RateLimit-Limit", "90", "X-RateLimit-Remaining", "88")(w, r)
+	})
+	c := NewClient(WithEndpoint(srv.URL))
+
+	media, err := c.MediaFromMediaID(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.Title.Romaji != "Cowboy Bebop" || *requests != 2 {
+		t.Errorf("got %q after %d requests, want a successful retry", media.Title.Romaji, *requests)
+	}
+	if status := c.RateLimit(); status.Limit != 90 || status.Remaining != 88 || !status.Reset.IsZero() {
+		t.Errorf("RateLimit() = %+v", status)
+	}
+}
+
+func TestRateLimitErrors(t *testing.T) {
+	srv, requests := standIn(t, reply(http.StatusTooManyRequests, `{"errors":[{"message":"Too Many Requests.","status":429}]}`,
+		"Retry-After", "60", "X-RateLimit-Limit", "90", "X-RateLimit-Remaining", "0"))
+	c := NewClient(WithEndpoint(srv.URL))
+
+	_, err := c.MediaFromMediaID(context.Background(), 1)
+	var rateLimited *RateLimitError
+	if !errors.As(err, &rateLimited) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected a *RateLimitError, got %v", err)
+	}
+	if rateLimited.RetryAfter != time.Minute {
+		t.Errorf("RetryAfter = %s", rateLimited.RetryAfter)
+	}
+	if *requests != 1 {
+		t.Errorf("a Retry-After longer than the limit was retried, %d requests", *requests)
+	}
+	if status := c.RateLimit(); status.Remaining != 0 || status.Reset.IsZero() {
+		t.Errorf("RateLimit() = %+v", status)
+	}
+
+	// Until the backoff is over, lookups fail without bothering AniList.
+	_, err = c.MediaFromMediaID(context.Background(), 1)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited while backing off, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("a request was sent while backing off, %d requests", *requests)
+	}
+}
+
+func TestWithRetries(t *testing.T) {
+	srv, requests := standIn(t, reply(http.StatusTooManyRequests, ``, "Retry-After", "0"))
+	c := NewClient(WithEndpoint(srv.URL), WithRetries(0, time.Second))
+
+	_, err := c.MediaFromMediaID(context.Background(), 1)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("WithRetries(0) made %d requests", *requests)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", []byte("1"), time.Minute)
+	cache.Set("b", []byte("2"), time.Minute)
+	// Reading a makes b the least recently used, so it's the one evicted.
+	if value, ok := cache.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Get(a) = %q, %v", value, ok)
+	}
+	cache.Set("c", []byte("3"), time.Minute)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+
+	cache.Set("a", []byte("4"), time.Minute)
+	if value, _ := cache.Get("a"); string(value) != "4" {
+		t.Errorf("Set didn't replace a, got %q", value)
+	}
+
+	cache.Set("expired", []byte("5"), -time.Second)
+	if _, ok := cache.Get("expired"); ok {
+		t.Error("expired entries shouldn't be returned")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", []byte(`{"x":1}`), time.Minute)
+	cache.Set("expired", []byte("2"), -time.Second)
+
+	// A new FileCache on the same directory sees what the old one stored.
+	reopened, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := reopened.Get("a"); !ok || string(value) != `{"x":1}` {
+		t.Errorf("Get(a) = %q, %v", value, ok)
+	}
+	if _, ok := reopened.Get("expired"); ok {
+		t.Error("expired entries shouldn't be returned")
+	}
+	if _, ok := reopened.Get("missing"); ok {
+		t.Error("missing entries shouldn't be returned")
+	}
+}
+
+// files lists the names in dir.
+func files(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
+
+func TestFileCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("live", []byte("1"), time.Minute)
+	cache.Set("expired", []byte("2"), -time.Second)
+	ioutil.WriteFile(filepath.Join(dir, "corrupt.json"), []byte("{"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "tmp-fresh"), nil, 0644)
+	ioutil.WriteFile(filepath.Join(dir, "tmp-stale"), nil, 0644)
+	old := time.Now().Add(-2 * staleTempFileAge)
+	os.Chtimes(filepath.Join(dir, "tmp-stale"), old, old)
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644)
+
+	removed, err := cache.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Errorf("removed %d files, expected expired, corrupt and tmp-stale", removed)
+	}
+	want := []string{"live.json", "notes.txt", "tmp-fresh"}
+	if got := files(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("left %v, want %v", got, want)
+	}
+}
+
+func TestFileCachePrunesOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("expired", []byte("1"), -time.Second)
+	if len(files(t, dir)) != 1 {
+		t.Fatal("expected the expired entry to be written")
+	}
+
+	if _, err := NewFileCache(dir); err != nil {
+		t.Fatal(err)
+	}
+	if left := files(t, dir); len(left) != 0 {
+		t.Errorf("expected opening the cache to prune it, left %v", left)
+	}
+}
+
+func TestFileCachePrunesWhileWriting(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < fileCacheSweepInterval-1; i++ {
+		cache.Set(strconv.Itoa(i), []byte("1"), -time.Second)
+	}
+	if n := len(files(t, dir)); n != fileCacheSweepInterval-1 {
+		t.Fatalf("expected no sweep before %d writes, have %d files", fileCacheSweepInterval, n)
+	}
+	cache.Set("last", []byte("1"), -time.Second)
+	if left := files(t, dir); len(left) != 0 {
+		t.Errorf("expected write %d to sweep the cache, left %d files", fileCacheSweepInterval, len(left))
+	}
+}
+
+// ttlCache is a MemoryCache that remembers the TTL each key was last set with.
+type ttlCache struct {
+	*MemoryCache
+	ttls map[string]time.Duration
+}
+
+func (c ttlCache) Set(key string, value []byte, ttl time.Duration) {
+	c.ttls[key] = ttl
+	c.MemoryCache.Set(key, value, ttl)
+}
+
+func TestOperationCacheTTLs(t *testing.T) {
+	srv, _ := standIn(t, reply(http.StatusOK, `{"data":{}}`))
+	ctx := context.Background()
+	tests := []struct {
+		operation string
+		clientTTL time.Duration
+		lookup    func(c *Client) error
+		want      time.Duration
+	}{
+		{"MediaByID", 6 * time.Hour, func(c *Client) error { _, err := c.MediaFromMediaID(ctx, 1); return err }, 6 * time.Hour},
+		{"MediaSearch", 6 * time.Hour, func(c *Client) error { _, err := c.MediaFromTitle(ctx, "bebop", 1); return err }, searchCacheTTL},
+		{"StaffSearch", 6 * time.Hour, func(c *Client) error { _, err := c.PeopleFromName(ctx, "watanabe", 1); return err }, searchCacheTTL},
+		{"AiringSchedules", 6 * time.Hour, func(c *Client) error {
+			_, err := c.AiringSchedulesBetween(ctx, time.Now(), time.Now().Add(time.Hour))
+			return err
+		}, airingCacheTTL},
+		// A Client that caches for less than an operation would isn't made to keep it longer.
+		{"MediaSearch", time.Minute, func(c *Client) error { _, err := c.MediaFromTitle(ctx, "bebop", 1); return err }, time.Minute},
+	}
+	for _, test := range tests {
+		cache := ttlCache{NewMemoryCache(10), map[string]time.Duration{}}
+		c := NewClient(WithEndpoint(srv.URL), WithCache(cache, test.clientTTL))
+		if err := test.lookup(c); err != nil {
+			t.Errorf("%s: %v", test.operation, err)
+			continue
+		}
+		if len(cache.ttls) != 1 {
+			t.Errorf("%s: expected one response to be cached, got %d", test.operation, len(cache.ttls))
+		}
+		for _, ttl := range cache.ttls {
+			if ttl != test.want {
+				t.Errorf("%s with a %s Client: cached for %s, want %s", test.operation, test.clientTTL, ttl, test.want)
+			}
+		}
+	}
+}
+
+func TestFileCacheBacksClient(t *testing.T) {
+	srv, requests := standIn(t, reply(http.StatusOK, bebop))
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		// A fresh Client each time, as if the bot had restarted.
+		cache, err := NewFileCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := NewClient(WithEndpoint(srv.URL), WithCache(cache, time.Minute))
+		media, err := c.MediaFromMediaID(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if media.Title.Romaji != "Cowboy Bebop" {
+			t.Errorf("run %d got %q", i, media.Title.Romaji)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("expected the file cache to outlive the Client, made %d requests", *requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		headers map[string]string
+		want    time.Duration
+	}{
+		{map[string]string{"Retry-After": "30"}, 30 * time.Second},
+		{map[string]string{"X-RateLimit-Reset": strconv.Itoa(1045)}, 45 * time.Second},
+		{map[string]string{"X-RateLimit-Reset": strconv.Itoa(900)}, rateLimitWindow},
+		{map[string]string{}, rateLimitWindow},
+	}
+	for _, test := range tests {
+		res := &http.Response{Header: http.Header{}}
+		for k, v := range test.headers {
+			res.Header.Set(k, v)
+		}
+		if got := retryAfter(res, now); got != test.want {
+			t.Errorf("retryAfter(%v) = %s, want %s", test.headers, got, test.want)
+		}
+	}
+}
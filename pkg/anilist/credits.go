@@ -0,0 +1,169 @@
+package anilist
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RoleGroup is a family of related staff roles, like everyone who directed a show.
+type RoleGroup string
+
+const (
+	RoleGroupDirection    RoleGroup = "Direction"
+	RoleGroupWriting      RoleGroup = "Writing"
+	RoleGroupMusic        RoleGroup = "Music"
+	RoleGroupArt          RoleGroup = "Art"
+	RoleGroupOriginalWork RoleGroup = "Original Work"
+	// RoleGroupOther is every role that isn't in one of the other groups.
+	RoleGroupOther RoleGroup = "Other"
+)
+
+// RoleGroups lists the groups roles are sorted into, leaving out RoleGroupOther.
+var RoleGroups = []RoleGroup{RoleGroupDirection, RoleGroupWriting, RoleGroupMusic, RoleGroupArt, RoleGroupOriginalWork}
+
+// roleRank places a normalized role within its group. A lower rank is more senior, so it's listed first.
+type roleRank struct {
+	group RoleGroup
+	rank  int
+}
+
+// roles are the roles we know how to group, keyed by their lowercased base role.
+// AniList's roles are free text, so these are the spellings it actually uses.
+var roles = map[string]roleRank{
+	"chief director":     {RoleGroupDirection, 0},
+	"director":           {RoleGroupDirection, 1},
+	"series director":    {RoleGroupDirection, 1},
+	"co-director":        {RoleGroupDirection, 2},
+	"assistant director": {RoleGroupDirection, 3},
+	"episode director":   {RoleGroupDirection, 4},
+	"unit director":      {RoleGroupDirection, 4},
+	"storyboard":         {RoleGroupDirection, 5},
+
+	"series composition": {RoleGroupWriting, 0},
+	"script":             {RoleGroupWriting, 1},
+	"screenplay":         {RoleGroupWriting, 1},
+	"scenario":           {RoleGroupWriting, 1},
+
+	"music":                   {RoleGroupMusic, 0},
+	"sound director":          {RoleGroupMusic, 1},
+	"theme song composition":  {RoleGroupMusic, 2},
+	"theme song lyrics":       {RoleGroupMusic, 2},
+	"theme song arrangement":  {RoleGroupMusic, 2},
+	"theme song performance":  {RoleGroupMusic, 3},
+	"insert song performance": {RoleGroupMusic, 4},
+
+	"original character design": {RoleGroupArt, 0},
+	"character design":          {RoleGroupArt, 0},
+	"chief animation director":  {RoleGroupArt, 1},
+	"art director":              {RoleGroupArt, 2},
+	"art design":                {RoleGroupArt, 2},
+	"mechanical design":         {RoleGroupArt, 3},
+	"color design":              {RoleGroupArt, 3},
+	"animation director":        {RoleGroupArt, 4},
+	"key animation":             {RoleGroupArt, 6},
+
+	"original creator": {RoleGroupOriginalWork, 0},
+	"original story":   {RoleGroupOriginalWork, 0},
+	"original work":    {RoleGroupOriginalWork, 0},
+	"story & art":      {RoleGroupOriginalWork, 0},
+	"story":            {RoleGroupOriginalWork, 1},
+	"art":              {RoleGroupOriginalWork, 1},
+	"original concept": {RoleGroupOriginalWork, 1},
+}
+
+// Roles we don't recognize still get grouped, behind everything we do.
+const unknownRank = 100
+
+// The highest rank Director will accept, so an episode director isn't mistaken for the director.
+const directorRank = 2
+
+// roleQualifier matches the parts of a role that say which episodes or songs it covers, like "(eps 1-6)" or "(OP)".
+var roleQualifier = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// Credit is a staff member's role on a Media.
+type Credit struct {
+	Staff Staff
+	// Role is how AniList credits them, e.g. "Key Animation (ep 3)".
+	Role string
+	// BaseRole is Role without its qualifiers, e.g. "Key Animation".
+	BaseRole string
+	Group    RoleGroup
+	rank     int
+}
+
+// NormalizeRole splits an AniList role into its base role and the group it belongs to.
+func NormalizeRole(role string) (string, RoleGroup) {
+	base := strings.TrimSpace(roleQualifier.ReplaceAllString(role, ""))
+	if known, ok := roles[strings.ToLower(base)]; ok {
+		return base, known.group
+	}
+	return base, RoleGroupOther
+}
+
+func newCredit(edge StaffEdge) Credit {
+	base, group := NormalizeRole(edge.Role)
+	rank := unknownRank
+	if known, ok := roles[strings.ToLower(base)]; ok {
+		rank = known.rank
+	}
+	return Credit{Staff: edge.Node, Role: edge.Role, BaseRole: base, Group: group, rank: rank}
+}
+
+// Credits lists everyone credited on media, in the order AniList gave them.
+// Someone with several roles is listed once for each.
+func (media Media) Credits() []Credit {
+	credits := make([]Credit, 0, len(media.Staff.Edges))
+	for _, edge := range media.Staff.Edges {
+		credits = append(credits, newCredit(edge))
+	}
+	return credits
+}
+
+// ByRole lists the credits whose base role is role, ignoring case, so ByRole("director")
+// finds "Director (eps 1-12)" as well as "Director".
+func (media Media) ByRole(role string) []Credit {
+	var credits []Credit
+	for _, credit := range media.Credits() {
+		if strings.EqualFold(credit.BaseRole, role) {
+			credits = append(credits, credit)
+		}
+	}
+	return credits
+}
+
+// ByGroup lists the credits in group, most senior role first. Someone with several roles in the
+// group is only listed for their most senior one.
+func (media Media) ByGroup(group RoleGroup) []Credit {
+	var credits []Credit
+	for _, credit := range media.Credits() {
+		if credit.Group == group {
+			credits = append(credits, credit)
+		}
+	}
+	// Stable, so AniList's ordering decides between people with the same role.
+	sort.SliceStable(credits, func(i, j int) bool {
+		return credits[i].rank < credits[j].rank
+	})
+
+	seen := make(map[int]bool)
+	unique := credits[:0]
+	for _, credit := range credits {
+		if !seen[credit.Staff.ID] {
+			seen[credit.Staff.ID] = true
+			unique = append(unique, credit)
+		}
+	}
+	return unique
+}
+
+// Leads lists the credits holding the most senior role in group, e.g. both directors of a co-directed show.
+func (media Media) Leads(group RoleGroup) []Credit {
+	credits := media.ByGroup(group)
+	for i, credit := range credits {
+		if credit.rank != credits[0].rank {
+			return credits[:i]
+		}
+	}
+	return credits
+}
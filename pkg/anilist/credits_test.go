@@ -0,0 +1,214 @@
+package anilist
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// credited is a media with the given staff, each an ID and the role they're credited with, in order.
+func credited(staff ...interface{}) Media {
+	var media Media
+	for i := 0; i < len(staff); i += 2 {
+		var edge StaffEdge
+		edge.Node.ID = staff[i].(int)
+		edge.Role = staff[i+1].(string)
+		media.Staff.Edges = append(media.Staff.Edges, edge)
+	}
+	return media
+}
+
+// ids lists the staff IDs of credits, in order.
+func ids(credits []Credit) []int {
+	list := []int{}
+	for _, credit := range credits {
+		list = append(list, credit.Staff.ID)
+	}
+	return list
+}
+
+func TestNormalizeRole(t *testing.T) {
+	tests := []struct {
+		role  string
+		base  string
+		group RoleGroup
+	}{
+		{"Director", "Director", RoleGroupDirection},
+		{"Director (eps 1-12)", "Director", RoleGroupDirection},
+		{"Chief Director", "Chief Director", RoleGroupDirection},
+		{"Episode Director (ep 3)", "Episode Director", RoleGroupDirection},
+		{"Storyboard (eps 1, 3, 5)", "Storyboard", RoleGroupDirection},
+		{"Series Composition", "Series Composition", RoleGroupWriting},
+		{"Theme Song Performance (OP)", "Theme Song Performance", RoleGroupMusic},
+		{"Theme Song Lyrics (ED) (eps 2-13)", "Theme Song Lyrics", RoleGroupMusic},
+		{"key animation (ep 3)", "key animation", RoleGroupArt},
+		{"Original Creator", "Original Creator", RoleGroupOriginalWork},
+		{"Story & Art", "Story & Art", RoleGroupOriginalWork},
+		{"Assistant Producer", "Assistant Producer", RoleGroupOther},
+		{"  In-Between Animation (ep 7)  ", "In-Between Animation", RoleGroupOther},
+		{"", "", RoleGroupOther},
+	}
+	for _, test := range tests {
+		base, group := NormalizeRole(test.role)
+		if base != test.base || group != test.group {
+			t.Errorf("NormalizeRole(%q) = %q, %q, want %q, %q", test.role, base, group, test.base, test.group)
+		}
+	}
+}
+
+func TestByGroup(t *testing.T) {
+	tests := []struct {
+		name  string
+		media Media
+		group RoleGroup
+		want  []int
+	}{
+		{
+			name:  "most senior first",
+			media: credited(1, "Episode Director (ep 2)", 2, "Storyboard", 3, "Chief Director", 4, "Director (eps 1-12)"),
+			group: RoleGroupDirection,
+			want:  []int{3, 4, 1, 2},
+		},
+		{
+			name:  "ties keep AniList's order",
+			media: credited(5, "Episode Director (ep 2)", 6, "Episode Director (ep 1)", 7, "Unit Director"),
+			group: RoleGroupDirection,
+			want:  []int{5, 6, 7},
+		},
+		{
+			name:  "listed once, for their most senior role",
+			media: credited(1, "Storyboard (eps 1-3)", 2, "Episode Director", 1, "Director", 1, "Episode Director (ep 5)"),
+			group: RoleGroupDirection,
+			want:  []int{1, 2},
+		},
+		{
+			name:  "only the group asked for",
+			media: credited(1, "Director", 2, "Music", 3, "Script (ep 1)", 4, "Producer"),
+			group: RoleGroupWriting,
+			want:  []int{3},
+		},
+		{
+			name:  "unknown roles",
+			media: credited(1, "Producer", 2, "Director", 3, "Planning"),
+			group: RoleGroupOther,
+			want:  []int{1, 3},
+		},
+		{
+			name:  "nothing credited",
+			media: credited(),
+			group: RoleGroupMusic,
+			want:  []int{},
+		},
+	}
+	for _, test := range tests {
+		if got := ids(test.media.ByGroup(test.group)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: ByGroup(%s) = %v, want %v", test.name, test.group, got, test.want)
+		}
+	}
+}
+
+func TestLeads(t *testing.T) {
+	tests := []struct {
+		name  string
+		media Media
+		group RoleGroup
+		want  []int
+	}{
+		{
+			name:  "a chief director outranks the director",
+			media: credited(1, "Director", 2, "Chief Director"),
+			group: RoleGroupDirection,
+			want:  []int{2},
+		},
+		{
+			name:  "co-directors",
+			media: credited(1, "Director (eps 1-6)", 2, "Episode Director", 3, "Director (eps 7-12)"),
+			group: RoleGroupDirection,
+			want:  []int{1, 3},
+		},
+		{
+			name:  "several original creators",
+			media: credited(1, "Original Creator", 2, "Original Story", 3, "Original Character Design", 4, "Story"),
+			group: RoleGroupOriginalWork,
+			want:  []int{1, 2},
+		},
+		{
+			name:  "story and art credited separately",
+			media: credited(1, "Story", 2, "Art"),
+			group: RoleGroupOriginalWork,
+			want:  []int{1, 2},
+		},
+		{
+			name:  "a lead with several roles is listed once",
+			media: credited(1, "Original Creator", 1, "Original Story", 2, "Story & Art"),
+			group: RoleGroupOriginalWork,
+			want:  []int{1, 2},
+		},
+		{
+			name:  "nothing credited",
+			media: credited(1, "Music"),
+			group: RoleGroupOriginalWork,
+			want:  []int{},
+		},
+	}
+	for _, test := range tests {
+		if got := ids(test.media.Leads(test.group)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Leads(%s) = %v, want %v", test.name, test.group, got, test.want)
+		}
+	}
+}
+
+func TestDirector(t *testing.T) {
+	tests := []struct {
+		name  string
+		media Media
+		want  int
+	}{
+		{"director", credited(1, "Episode Director (ep 1)", 2, "Director"), 2},
+		{"chief director over director", credited(1, "Director", 2, "Chief Director"), 2},
+		{"with episode qualifiers", credited(1, "Director (eps 1-12)"), 1},
+		{"co-director", credited(1, "Storyboard", 2, "Co-Director"), 2},
+		// An episode director only directed part of it, so it's better to say nobody did.
+		{"only episode directors", credited(1, "Episode Director (ep 1)", 2, "Storyboard"), 0},
+		{"nobody", credited(1, "Music"), 0},
+	}
+	for _, test := range tests {
+		director, err := test.media.Director()
+		if test.want == 0 {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("%s: expected ErrNotFound, got %d, %v", test.name, director.ID, err)
+			}
+			continue
+		}
+		if err != nil || director.ID != test.want {
+			t.Errorf("%s: Director() = %d, %v, want %d", test.name, director.ID, err, test.want)
+		}
+	}
+}
+
+func TestCreator(t *testing.T) {
+	tests := []struct {
+		name  string
+		media Media
+		want  int
+	}{
+		{"original creator", credited(1, "Director", 2, "Original Creator"), 2},
+		{"manga author", credited(1, "Story & Art"), 1},
+		{"first of several", credited(1, "Original Story", 2, "Original Creator"), 1},
+		{"ties keep AniList's order", credited(1, "Art", 2, "Story"), 1},
+		{"original work outranks story", credited(1, "Story", 2, "Original Work"), 2},
+		{"nobody", credited(1, "Director"), 0},
+	}
+	for _, test := range tests {
+		creator, err := test.media.Creator()
+		if test.want == 0 {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("%s: expected ErrNotFound, got %d, %v", test.name, creator.ID, err)
+			}
+			continue
+		}
+		if err != nil || creator.ID != test.want {
+			t.Errorf("%s: Creator() = %d, %v, want %d", test.name, creator.ID, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package anilist
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Every error returned by a Client matches one of these with errors.Is, so callers can tell
+// what went wrong without picking apart messages.
+var (
+	// ErrNotFound means AniList has nothing matching the lookup.
+	ErrNotFound = errors.New("anilist: not found")
+	// ErrInvalidQuery means the lookup was malformed, either before it was sent or according to AniList.
+	ErrInvalidQuery = errors.New("anilist: invalid query")
+	// ErrRateLimited means AniList refused the request because our request budget is used up.
+	// The error will also be a *RateLimitError, saying how long to wait.
+	ErrRateLimited = errors.New("anilist: rate limited")
+	// ErrUpstream means AniList answered, but with an error. The error will also be an *UpstreamError.
+	ErrUpstream = errors.New("anilist: upstream error")
+	// ErrNetwork means AniList couldn't be reached, or the connection failed part way through.
+	// The error will also be a *NetworkError.
+	ErrNetwork = errors.New("anilist: network error")
+)
+
+// Is makes a *RateLimitError match ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// Location is where in a query a GraphQL error was found.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is one of the errors in a GraphQL response.
+type GraphQLError struct {
+	Message string `json:"message"`
+	// Status is the HTTP status AniList attaches to the error, if any.
+	Status    int        `json:"status"`
+	Locations []Location `json:"locations"`
+}
+
+func (e GraphQLError) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+	locations := make([]string, 0, len(e.Locations))
+	for _, l := range e.Locations {
+		locations = append(locations, fmt.Sprintf("%d:%d", l.Line, l.Column))
+	}
+	return fmt.Sprintf("%s (at %s)", e.Message, strings.Join(locations, ", "))
+}
+
+// UpstreamError is returned when AniList answers a request with an error, either in the GraphQL
+// response or as an HTTP error status. It matches ErrUpstream, and also ErrNotFound, ErrInvalidQuery
+// or ErrRateLimited when its status says that's what happened.
+type UpstreamError struct {
+	// Status is the HTTP status of the response, or of the first GraphQL error if that says more.
+	Status int
+	// Errors are the GraphQL errors in the response, empty if it wasn't a GraphQL response at all.
+	Errors []GraphQLError
+}
+
+func (e *UpstreamError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("anilist: %d %s", e.Status, http.StatusText(e.Status))
+	}
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("anilist: %d %s", e.Status, strings.Join(messages, "; "))
+}
+
+func (e *UpstreamError) Is(target error) bool {
+	switch target {
+	case ErrUpstream:
+		return true
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrInvalidQuery:
+		return e.Status == http.StatusBadRequest
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// NetworkError is returned when a request couldn't be sent or its response couldn't be read.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return "anilist: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrNetwork
+}
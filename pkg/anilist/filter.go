@@ -0,0 +1,76 @@
+package anilist
+
+// filterVars sets the variables for each of query's filters that are in use. AniList compares
+// ranges exclusively, so the bounds sent are nudged one past the ones asked for.
+func (query MediaQuery) filterVars(vars map[string]interface{}) {
+	if len(query.Genres) > 0 {
+		vars["genres"] = query.Genres
+	}
+	if len(query.ExcludedGenres) > 0 {
+		vars["excludedGenres"] = query.ExcludedGenres
+	}
+	if len(query.Tags) > 0 {
+		vars["tags"] = query.Tags
+	}
+	if len(query.Formats) > 0 {
+		vars["formats"] = query.Formats
+	}
+	if query.Status != "" {
+		vars["status"] = query.Status
+	}
+	if query.Season != "" {
+		vars["season"] = query.Season
+	}
+	if query.SeasonYear != 0 {
+		vars["seasonYear"] = query.SeasonYear
+	}
+	if query.StartDateFrom != (FuzzyDate{}) {
+		vars["startAfter"] = query.StartDateFrom.earliest() - 1
+	}
+	if query.StartDateTo != (FuzzyDate{}) {
+		vars["startBefore"] = query.StartDateTo.latest() + 1
+	}
+	if query.MinScore != 0 {
+		vars["scoreAbove"] = query.MinScore - 1
+	}
+	if query.MaxScore != 0 {
+		vars["scoreBelow"] = query.MaxScore + 1
+	}
+	if query.MinPopularity != 0 {
+		vars["popularityAbove"] = query.MinPopularity - 1
+	}
+	if query.MaxPopularity != 0 {
+		vars["popularityBelow"] = query.MaxPopularity + 1
+	}
+	if query.IsAdult != nil {
+		vars["isAdult"] = *query.IsAdult
+	}
+	if query.CountryOfOrigin != "" {
+		vars["country"] = query.CountryOfOrigin
+	}
+	if query.Source != "" {
+		vars["source"] = query.Source
+	}
+}
+
+// Int is the date in AniList's FuzzyDateInt form, YYYYMMDD with unknown parts as 0.
+func (d FuzzyDate) Int() int {
+	return d.Year*10000 + d.Month*100 + d.Day
+}
+
+// earliest is the FuzzyDateInt of the start of the period d covers. Unknown parts are left as 0,
+// so media with equally fuzzy start dates are included too.
+func (d FuzzyDate) earliest() int {
+	return d.Int()
+}
+
+// latest is the FuzzyDateInt of the end of the period d covers.
+func (d FuzzyDate) latest() int {
+	if d.Month == 0 {
+		d.Month = 12
+	}
+	if d.Day == 0 {
+		d.Day = 31
+	}
+	return d.Int()
+}
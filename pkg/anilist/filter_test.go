@@ -0,0 +1,121 @@
+package anilist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyDateBounds(t *testing.T) {
+	tests := []struct {
+		date     FuzzyDate
+		earliest int
+		latest   int
+	}{
+		{FuzzyDate{Year: 1998}, 19980000, 19981231},
+		{FuzzyDate{Year: 1998, Month: 4}, 19980400, 19980431},
+		{FuzzyDate{Year: 1998, Month: 4, Day: 3}, 19980403, 19980403},
+		{FuzzyDate{Year: 1998, Day: 3}, 19980003, 19981203},
+		{FuzzyDate{Year: 2000, Month: 2}, 20000200, 20000231},
+	}
+	for _, test := range tests {
+		if got := test.date.earliest(); got != test.earliest {
+			t.Errorf("%+v.earliest() = %d, want %d", test.date, got, test.earliest)
+		}
+		if got := test.date.latest(); got != test.latest {
+			t.Errorf("%+v.latest() = %d, want %d", test.date, got, test.latest)
+		}
+	}
+}
+
+func TestFilterVars(t *testing.T) {
+	notAdult := false
+	tests := []struct {
+		name  string
+		query MediaQuery
+		want  map[string]interface{}
+	}{
+		{"nothing set", MediaQuery{}, map[string]interface{}{}},
+		{
+			name: "lists and enums",
+			query: MediaQuery{
+				Genres:          []string{"Action", "Comedy"},
+				ExcludedGenres:  []string{"Horror"},
+				Tags:            []string{"Space"},
+				Formats:         []MediaFormat{MediaFormatTV},
+				Status:          MediaStatusFinished,
+				Season:          MediaSeasonSpring,
+				SeasonYear:      1998,
+				IsAdult:         &notAdult,
+				CountryOfOrigin: "JP",
+				Source:          MediaSourceOriginal,
+			},
+			want: map[string]interface{}{
+				"genres":         []string{"Action", "Comedy"},
+				"excludedGenres": []string{"Horror"},
+				"tags":           []string{"Space"},
+				"formats":        []MediaFormat{MediaFormatTV},
+				"status":         MediaStatusFinished,
+				"season":         MediaSeasonSpring,
+				"seasonYear":     1998,
+				"isAdult":        false,
+				"country":        "JP",
+				"source":         MediaSourceOriginal,
+			},
+		},
+		{
+			// AniList's bounds are exclusive, so each is sent one past the inclusive bound asked for.
+			name:  "score and popularity",
+			query: MediaQuery{MinScore: 70, MaxScore: 90, MinPopularity: 1000, MaxPopularity: 5000},
+			want:  map[string]interface{}{"scoreAbove": 69, "scoreBelow": 91, "popularityAbove": 999, "popularityBelow": 5001},
+		},
+		{
+			name:  "a year",
+			query: MediaQuery{StartDateFrom: FuzzyDate{Year: 1998}, StartDateTo: FuzzyDate{Year: 1998}},
+			want:  map[string]interface{}{"startAfter": 19979999, "startBefore": 19981232},
+		},
+		{
+			name:  "exact dates",
+			query: MediaQuery{StartDateFrom: FuzzyDate{Year: 1998, Month: 4, Day: 3}, StartDateTo: FuzzyDate{Year: 1999, Month: 4, Day: 24}},
+			want:  map[string]interface{}{"startAfter": 19980402, "startBefore": 19990425},
+		},
+		{
+			name:  "only one end",
+			query: MediaQuery{StartDateTo: FuzzyDate{Year: 2000, Month: 6}, MaxScore: 50},
+			want:  map[string]interface{}{"startBefore": 20000632, "scoreBelow": 51},
+		},
+	}
+	for _, test := range tests {
+		got := map[string]interface{}{}
+		test.query.filterVars(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: filterVars set %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestDateBoundsIncludeTheirEnds checks the dates on either edge of a range, and dates AniList only knows
+// part of, fall inside it once the bounds are nudged for AniList's exclusive comparison.
+func TestDateBoundsIncludeTheirEnds(t *testing.T) {
+	query := MediaQuery{StartDateFrom: FuzzyDate{Year: 1998, Month: 4}, StartDateTo: FuzzyDate{Year: 1999}}
+	vars := map[string]interface{}{}
+	query.filterVars(vars)
+	after, before := vars["startAfter"].(int), vars["startBefore"].(int)
+
+	tests := []struct {
+		start  FuzzyDate
+		inside bool
+	}{
+		{FuzzyDate{Year: 1998, Month: 4, Day: 1}, true},
+		{FuzzyDate{Year: 1998, Month: 4}, true},
+		{FuzzyDate{Year: 1999, Month: 12, Day: 31}, true},
+		{FuzzyDate{Year: 1999}, true},
+		{FuzzyDate{Year: 1998, Month: 3, Day: 31}, false},
+		{FuzzyDate{Year: 2000, Month: 1, Day: 1}, false},
+	}
+	for _, test := range tests {
+		date := test.start.Int()
+		if inside := date > after && date < before; inside != test.inside {
+			t.Errorf("%+v: inside the range is %t, want %t", test.start, inside, test.inside)
+		}
+	}
+}
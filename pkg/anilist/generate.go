@@ -0,0 +1,5 @@
+package anilist
+
+// The enums and object types in schema_gen.go are generated from the snapshot of the AniList schema in schema.graphql.
+// Media and AiringSchedule refer to each other, so one of them has to hold the other by pointer.
+//go:generate go run ./internal/schemagen -schema schema.graphql -out schema_gen.go -package anilist -pointers Media.nextAiringEpisode
@@ -0,0 +1,399 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// token is a lexical token of the schema definition language. Strings have their quotes and
+// indentation stripped, and comments and commas are dropped, since neither means anything.
+type token struct {
+	text string
+	str  bool
+	line int
+}
+
+func lex(source string) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(source[i+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated block string", line)
+			}
+			text := source[i+3 : i+3+end]
+			tokens = append(tokens, token{text: blockString(text), str: true, line: line})
+			line += strings.Count(text, "\n")
+			i += end + 6
+		case c == '"':
+			end := i + 1
+			for end < len(source) && source[end] != '"' && source[end] != '\n' {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) || source[end] != '"' {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, token{text: strings.ReplaceAll(source[i+1:end], `\"`, `"`), str: true, line: line})
+			i = end + 1
+		case strings.ContainsRune("{}()[]:!=@&|$", rune(c)):
+			tokens = append(tokens, token{text: string(c), line: line})
+			i++
+		case c == '_' || c == '-' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+			end := i
+			for end < len(source) && (source[end] == '_' || source[end] == '-' || source[end] == '.' ||
+				unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{text: source[i:end], line: line})
+			i = end
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", line, c)
+		}
+	}
+	return tokens, nil
+}
+
+// blockString strips the common indentation and surrounding blank lines from a """ string.
+func blockString(text string) string {
+	lines := strings.Split(text, "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		if len(lines[i]) >= indent && indent > 0 {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of input")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.str || t.text != text {
+		return fmt.Errorf("line %d: expected %q, found %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return !p.done() && !t.str && t.text == text
+}
+
+// description consumes the description string in front of a definition, field or value, if any.
+func (p *parser) description() string {
+	if !p.done() && p.peek().str {
+		t, _ := p.next()
+		return t.text
+	}
+	return ""
+}
+
+// skipBalanced skips from an opening bracket to its matching close.
+func (p *parser) skipBalanced(open string, close string) error {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.str {
+			continue
+		}
+		switch t.text {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// directives consumes any directives, returning the reason given by @deprecated if it's there.
+func (p *parser) directives() (string, error) {
+	deprecated := ""
+	for p.is("@") {
+		p.next()
+		name, err := p.next()
+		if err != nil {
+			return "", err
+		}
+		if name.text == "deprecated" {
+			deprecated = "No longer supported by AniList."
+		}
+		if p.is("(") {
+			start := p.pos
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return "", err
+			}
+			if name.text == "deprecated" {
+				for _, t := range p.tokens[start:p.pos] {
+					if t.str {
+						deprecated = t.text
+					}
+				}
+			}
+		}
+	}
+	return deprecated, nil
+}
+
+func (p *parser) typeRef() (TypeRef, error) {
+	var ref TypeRef
+	if p.is("[") {
+		p.next()
+		element, err := p.typeRef()
+		if err != nil {
+			return ref, err
+		}
+		if err := p.expect("]"); err != nil {
+			return ref, err
+		}
+		ref.List = &element
+	} else {
+		name, err := p.next()
+		if err != nil {
+			return ref, err
+		}
+		ref.Name = name.text
+	}
+	if p.is("!") {
+		p.next()
+		ref.NonNull = true
+	}
+	return ref, nil
+}
+
+// Parse reads a schema written in the schema definition language.
+func Parse(source string) (*Schema, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	s := &Schema{ByName: map[string]*Definition{}}
+
+	for !p.done() {
+		description := p.description()
+		keyword, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if keyword.text == "extend" {
+			return nil, fmt.Errorf("line %d: extensions aren't supported", keyword.line)
+		}
+
+		switch keyword.text {
+		case "schema":
+			if err := p.skipBalanced("{", "}"); err != nil {
+				return nil, err
+			}
+			continue
+		case "directive":
+			// directive @name(args) on LOCATION | LOCATION
+			for !p.done() && !p.is("on") {
+				if p.is("(") {
+					if err := p.skipBalanced("(", ")"); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				p.next()
+			}
+			p.next()
+			p.next()
+			for p.is("|") {
+				p.next()
+				p.next()
+			}
+			continue
+		}
+
+		name, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		d := &Definition{Keyword: keyword.text, Name: name.text, Description: description}
+
+		switch keyword.text {
+		case "scalar":
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+		case "union":
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			p.next()
+			for p.is("|") {
+				p.next()
+				p.next()
+			}
+		case "type", "interface", "input":
+			if p.is("implements") {
+				p.next()
+				for !p.done() && !p.is("{") && !p.is("@") {
+					p.next()
+				}
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if err := p.fields(d); err != nil {
+				return nil, err
+			}
+		case "enum":
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			if err := p.values(d); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", keyword.line, keyword.text)
+		}
+
+		if _, ok := s.ByName[d.Name]; ok {
+			return nil, fmt.Errorf("line %d: %s is defined twice", name.line, d.Name)
+		}
+		s.ByName[d.Name] = d
+		s.Definitions = append(s.Definitions, d)
+	}
+	return s, nil
+}
+
+func (p *parser) fields(d *Definition) error {
+	fields, err := p.inputValues("{", "}")
+	d.Fields = fields
+	return err
+}
+
+// inputValues reads the fields between open and close, each with a type and, for object fields, maybe arguments.
+func (p *parser) inputValues(open string, close string) ([]Field, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+	var fields []Field
+	for !p.is(close) {
+		f := Field{Description: p.description()}
+		name, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		f.Name = name.text
+		if p.is("(") {
+			if f.Args, err = p.inputValues("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if f.Kind, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		// Arguments and input fields can have defaults.
+		if p.is("=") {
+			if err := p.skipValue(); err != nil {
+				return nil, err
+			}
+		}
+		if f.Deprecated, err = p.directives(); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	p.next()
+	return fields, nil
+}
+
+// skipValue skips past = and the default value after it.
+func (p *parser) skipValue() error {
+	p.next()
+	var err error
+	if p.is("[") {
+		err = p.skipBalanced("[", "]")
+	} else if p.is("{") {
+		err = p.skipBalanced("{", "}")
+	} else {
+		_, err = p.next()
+	}
+	return err
+}
+
+func (p *parser) values(d *Definition) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.is("}") {
+		description := p.description()
+		name, err := p.next()
+		if err != nil {
+			return err
+		}
+		deprecated, err := p.directives()
+		if err != nil {
+			return err
+		}
+		d.Values = append(d.Values, Field{Name: name.text, Description: description, Deprecated: deprecated})
+	}
+	p.next()
+	return nil
+}
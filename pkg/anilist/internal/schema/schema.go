@@ -0,0 +1,108 @@
+// Package schema reads a GraphQL schema, as much of the schema definition language as the AniList
+// schema uses, and checks queries against it. schemagen uses it to generate pkg/anilist's types,
+// and pkg/anilist's tests use it to check every query the package sends.
+package schema
+
+// TypeRef is a reference to a type from a field or argument, e.g. [MediaSort]!.
+type TypeRef struct {
+	Name    string
+	List    *TypeRef
+	NonNull bool
+}
+
+// Innermost is the named type at the bottom of any lists, e.g. MediaSort for [MediaSort]!.
+func (t TypeRef) Innermost() TypeRef {
+	for t.List != nil {
+		t = *t.List
+	}
+	return t
+}
+
+func (t TypeRef) String() string {
+	s := t.Name
+	if t.List != nil {
+		s = "[" + t.List.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Field is a field of an object or input type, an argument to a field, or an enum value.
+type Field struct {
+	Name        string
+	Description string
+	Deprecated  string
+	// Kind is unset for enum values.
+	Kind TypeRef
+	// Args are the arguments an object field takes.
+	Args []Field
+}
+
+// Definition is a named type.
+type Definition struct {
+	// Keyword is how the type was defined: type, input, enum, scalar, interface or union.
+	Keyword     string
+	Name        string
+	Description string
+	Fields      []Field
+	Values      []Field
+}
+
+// Field looks up one of d's fields by name.
+func (d *Definition) Field(name string) (Field, bool) {
+	for _, f := range d.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Value reports whether d is an enum with value among its values.
+func (d *Definition) Value(value string) bool {
+	for _, v := range d.Values {
+		if v.Name == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Schema is every type a schema defines, in the order it defines them.
+type Schema struct {
+	Definitions []*Definition
+	ByName      map[string]*Definition
+}
+
+// builtins are the scalars every schema has without defining them.
+var builtins = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// Type looks up the named type, including the built in scalars.
+func (s *Schema) Type(name string) (*Definition, bool) {
+	if builtins[name] {
+		return &Definition{Keyword: "scalar", Name: name}, true
+	}
+	d, ok := s.ByName[name]
+	return d, ok
+}
+
+// HasField reports whether the type called typeName has a field called fieldName.
+func (s *Schema) HasField(typeName string, fieldName string) bool {
+	d, ok := s.ByName[typeName]
+	if !ok {
+		return false
+	}
+	_, ok = d.Field(fieldName)
+	return ok
+}
+
+// isComposite reports whether d is a type whose fields have to be selected.
+func (d *Definition) isComposite() bool {
+	switch d.Keyword {
+	case "type", "interface", "union":
+		return true
+	}
+	return false
+}
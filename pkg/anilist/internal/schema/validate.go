@@ -0,0 +1,303 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The type queries start from.
+const queryRoot = "Query"
+
+// validator checks a query document against a schema as it reads it.
+type validator struct {
+	*parser
+	schema *Schema
+	// variables are the query's variable definitions, by name.
+	variables map[string]TypeRef
+}
+
+// Validate checks that document, a single query, only asks for fields and passes arguments that s
+// has, that arguments get values of the right type, and that fields are selected exactly when
+// their type has fields of its own. Fragments and directives aren't supported, since the queries
+// pkg/anilist sends don't use them.
+func (s *Schema) Validate(document string) error {
+	tokens, err := lex(document)
+	if err != nil {
+		return err
+	}
+	v := &validator{parser: &parser{tokens: tokens}, schema: s, variables: map[string]TypeRef{}}
+
+	if v.is("query") {
+		v.next()
+		if !v.is("(") && !v.is("{") {
+			v.next()
+		}
+	}
+	if v.is("(") {
+		if err := v.variableDefinitions(); err != nil {
+			return err
+		}
+	}
+	root, ok := s.ByName[queryRoot]
+	if !ok {
+		return fmt.Errorf("the schema has no %s type", queryRoot)
+	}
+	if err := v.selectionSet(root); err != nil {
+		return err
+	}
+	if !v.done() {
+		return fmt.Errorf("line %d: unexpected %q after the query", v.peek().line, v.peek().text)
+	}
+	return nil
+}
+
+func (v *validator) variableDefinitions() error {
+	v.next()
+	for !v.is(")") {
+		if err := v.expect("$"); err != nil {
+			return err
+		}
+		name, err := v.next()
+		if err != nil {
+			return err
+		}
+		if err := v.expect(":"); err != nil {
+			return err
+		}
+		kind, err := v.typeRef()
+		if err != nil {
+			return err
+		}
+		d, ok := v.schema.Type(kind.Innermost().Name)
+		if !ok {
+			return fmt.Errorf("line %d: $%s has unknown type %s", name.line, name.text, kind)
+		}
+		if d.Keyword != "scalar" && d.Keyword != "enum" && d.Keyword != "input" {
+			return fmt.Errorf("line %d: $%s is a %s, which can't be an input", name.line, name.text, d.Keyword)
+		}
+		if _, ok := v.variables[name.text]; ok {
+			return fmt.Errorf("line %d: $%s is defined twice", name.line, name.text)
+		}
+		v.variables[name.text] = kind
+		if v.is("=") {
+			if err := v.skipValue(); err != nil {
+				return err
+			}
+		}
+	}
+	v.next()
+	return nil
+}
+
+// selectionSet checks the fields selected from parent, starting at their opening brace.
+func (v *validator) selectionSet(parent *Definition) error {
+	if err := v.expect("{"); err != nil {
+		return err
+	}
+	if v.is("}") {
+		return fmt.Errorf("line %d: nothing is selected from %s", v.peek().line, parent.Name)
+	}
+	for !v.is("}") {
+		if err := v.selection(parent); err != nil {
+			return err
+		}
+	}
+	v.next()
+	return nil
+}
+
+func (v *validator) selection(parent *Definition) error {
+	name, err := v.next()
+	if err != nil {
+		return err
+	}
+	if name.str {
+		return fmt.Errorf("line %d: expected a field, found a string", name.line)
+	}
+	// An alias comes before the field it renames.
+	if v.is(":") {
+		v.next()
+		if name, err = v.next(); err != nil {
+			return err
+		}
+	}
+	if name.text == "@" || name.text == "$" || name.text == "{" {
+		return fmt.Errorf("line %d: expected a field, found %q", name.line, name.text)
+	}
+
+	if name.text == "__typename" {
+		return nil
+	}
+	f, ok := parent.Field(name.text)
+	if !ok {
+		return fmt.Errorf("line %d: %s has no field %s", name.line, parent.Name, name.text)
+	}
+	if v.is("(") {
+		if err := v.arguments(parent.Name, f); err != nil {
+			return err
+		}
+	}
+
+	kind, ok := v.schema.Type(f.Kind.Innermost().Name)
+	if !ok {
+		return fmt.Errorf("%s.%s has unknown type %s", parent.Name, f.Name, f.Kind)
+	}
+	switch {
+	case kind.isComposite() && !v.is("{"):
+		return fmt.Errorf("line %d: %s.%s is a %s, so its fields have to be selected", name.line, parent.Name, f.Name, kind.Name)
+	case kind.isComposite():
+		return v.selectionSet(kind)
+	case v.is("{"):
+		return fmt.Errorf("line %d: %s.%s is a %s, which has no fields to select", name.line, parent.Name, f.Name, kind.Name)
+	}
+	return nil
+}
+
+// arguments checks the arguments passed to f, a field of the type called parent.
+func (v *validator) arguments(parent string, f Field) error {
+	v.next()
+	seen := map[string]bool{}
+	for !v.is(")") {
+		name, err := v.next()
+		if err != nil {
+			return err
+		}
+		var arg *Field
+		for i := range f.Args {
+			if f.Args[i].Name == name.text {
+				arg = &f.Args[i]
+			}
+		}
+		if arg == nil {
+			return fmt.Errorf("line %d: %s.%s has no argument %s", name.line, parent, f.Name, name.text)
+		}
+		if seen[name.text] {
+			return fmt.Errorf("line %d: %s.%s is given %s twice", name.line, parent, f.Name, name.text)
+		}
+		seen[name.text] = true
+		if err := v.expect(":"); err != nil {
+			return err
+		}
+		if err := v.value(arg.Kind); err != nil {
+			return fmt.Errorf("%s.%s(%s): %w", parent, f.Name, name.text, err)
+		}
+	}
+	v.next()
+	return nil
+}
+
+// value checks the value that comes next can be given where want is expected.
+func (v *validator) value(want TypeRef) error {
+	t, err := v.next()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !t.str && t.text == "$":
+		name, err := v.next()
+		if err != nil {
+			return err
+		}
+		kind, ok := v.variables[name.text]
+		if !ok {
+			return fmt.Errorf("line %d: $%s isn't defined", name.line, name.text)
+		}
+		if !assignable(kind, want) {
+			return fmt.Errorf("line %d: $%s is %s, but %s is expected", name.line, name.text, kind, want)
+		}
+		return nil
+	case !t.str && t.text == "null":
+		if want.NonNull {
+			return fmt.Errorf("line %d: null given where %s is expected", t.line, want)
+		}
+		return nil
+	case !t.str && t.text == "[":
+		if want.List == nil {
+			return fmt.Errorf("line %d: a list given where %s is expected", t.line, want)
+		}
+		for !v.is("]") {
+			if err := v.value(*want.List); err != nil {
+				return err
+			}
+		}
+		v.next()
+		return nil
+	}
+
+	// A single value can be given where a list of them is expected.
+	want = want.Innermost()
+	d, ok := v.schema.Type(want.Name)
+	if !ok {
+		return fmt.Errorf("unknown type %s", want.Name)
+	}
+
+	if !t.str && t.text == "{" {
+		if d.Keyword != "input" {
+			return fmt.Errorf("line %d: an object given where %s is expected", t.line, want)
+		}
+		for !v.is("}") {
+			name, err := v.next()
+			if err != nil {
+				return err
+			}
+			f, ok := d.Field(name.text)
+			if !ok {
+				return fmt.Errorf("line %d: %s has no field %s", name.line, d.Name, name.text)
+			}
+			if err := v.expect(":"); err != nil {
+				return err
+			}
+			if err := v.value(f.Kind); err != nil {
+				return err
+			}
+		}
+		v.next()
+		return nil
+	}
+
+	if !literalFits(t, d) {
+		return fmt.Errorf("line %d: %q can't be given as %s", t.line, t.text, want.Name)
+	}
+	return nil
+}
+
+// literalFits reports whether t, a string, number, boolean or enum value, can be given as a d.
+func literalFits(t token, d *Definition) bool {
+	if d.Keyword == "enum" {
+		return !t.str && d.Value(t.text)
+	}
+	if d.Keyword != "scalar" {
+		return false
+	}
+	_, intErr := strconv.ParseInt(t.text, 10, 32)
+	switch d.Name {
+	case "Int":
+		return !t.str && intErr == nil
+	case "Float":
+		_, err := strconv.ParseFloat(t.text, 64)
+		return !t.str && err == nil
+	case "Boolean":
+		return !t.str && (t.text == "true" || t.text == "false")
+	case "String":
+		return t.str
+	case "ID":
+		return t.str || intErr == nil
+	}
+	// The schema's own scalars could be anything, as long as it isn't an enum value.
+	return t.str || intErr == nil || t.text == "true" || t.text == "false"
+}
+
+// assignable reports whether a variable of type kind can be used where want is expected.
+func assignable(kind TypeRef, want TypeRef) bool {
+	if want.NonNull && !kind.NonNull {
+		return false
+	}
+	if (kind.List == nil) != (want.List == nil) {
+		return false
+	}
+	if kind.List != nil {
+		return assignable(*kind.List, *want.List)
+	}
+	return kind.Name == want.Name
+}
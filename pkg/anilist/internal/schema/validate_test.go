@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `
+scalar FuzzyDateInt
+
+type Query {
+  Page(page: Int, perPage: Int): Page
+  Media(id: Int, type: MediaType, sort: [MediaSort], startDate: FuzzyDateInt, filter: MediaFilter): Media
+}
+
+type Page {
+  media(search: String, isAdult: Boolean): [Media]
+}
+
+type Media {
+  id: Int!
+  type: MediaType
+  title: MediaTitle
+  relations(page: Int!): [Media]
+}
+
+type MediaTitle {
+  romaji(stylised: Boolean): String
+}
+
+input MediaFilter {
+  type: MediaType
+}
+
+enum MediaType {
+  ANIME
+  MANGA
+}
+
+enum MediaSort {
+  ID
+  TITLE_ROMAJI
+}
+`
+
+func TestValidate(t *testing.T) {
+	s, err := Parse(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []string{
+		`{ Media { id } }`,
+		`query { Media { id } }`,
+		`query Named { Media { id title { romaji } } }`,
+		`query ($id: Int, $type: MediaType) { Media(id: $id, type: $type) { id } }`,
+		`query ($id: Int!) { Media(id: $id) { id } }`,
+		`query ($sort: [MediaSort]) { Media(sort: $sort) { id } }`,
+		`query ($date: FuzzyDateInt) { Media(startDate: $date) { id } }`,
+		`{ Media(id: 1, type: ANIME, sort: [ID, TITLE_ROMAJI]) { id } }`,
+		`{ Media(sort: ID) { id } }`,
+		`{ Media(id: null) { id } }`,
+		`{ Media(startDate: 20200101) { id } }`,
+		`{ Media(filter: {type: MANGA}) { id } }`,
+		`{ Page(page: 1, perPage: 5) { media(search: "bebop", isAdult: false) { id } } }`,
+		`{ first: Media(id: 1) { id } second: Media(id: 2) { english: title { romaji(stylised: true) } } }`,
+		`{ Media { __typename id } }`,
+	}
+	for _, query := range valid {
+		if err := s.Validate(query); err != nil {
+			t.Errorf("Validate(%s): %v", query, err)
+		}
+	}
+
+	invalid := []struct {
+		query    string
+		mentions string
+	}{
+		{`{ Media { idMal } }`, "Media has no field idMal"},
+		{`{ Anime { id } }`, "Query has no field Anime"},
+		{`{ Media(idMal: 1) { id } }`, "has no argument idMal"},
+		{`{ Media(id: 1, id: 2) { id } }`, "given id twice"},
+		{`{ Media(type: NOVEL) { id } }`, `"NOVEL" can't be given as MediaType`},
+		{`{ Media(type: "ANIME") { id } }`, `can't be given as MediaType`},
+		{`{ Media(sort: [ID, POPULARITY]) { id } }`, `"POPULARITY" can't be given as MediaSort`},
+		{`{ Media(id: "1") { id } }`, "can't be given as Int"},
+		{`{ Media(id: [1]) { id } }`, "a list given"},
+		{`{ Media(filter: {format: TV}) { id } }`, "MediaFilter has no field format"},
+		{`{ Page { media(isAdult: no) { id } } }`, `"no" can't be given as Boolean`},
+		{`{ Media { relations(page: null) { id } } }`, "null given"},
+		{`{ Media(id: $id) { id } }`, "$id isn't defined"},
+		{`query ($id: String) { Media(id: $id) { id } }`, "$id is String, but Int is expected"},
+		{`query ($sort: MediaSort) { Media(sort: $sort) { id } }`, "$sort is MediaSort, but [MediaSort] is expected"},
+		{`query ($page: Int) { Media { relations(page: $page) { id } } }`, "$page is Int, but Int! is expected"},
+		{`query ($id: Integer) { Media(id: $id) { id } }`, "unknown type Integer"},
+		{`query ($m: Media) { Media { id } }`, "can't be an input"},
+		{`query ($id: Int, $id: Int) { Media(id: $id) { id } }`, "$id is defined twice"},
+		{`{ Media }`, "its fields have to be selected"},
+		{`{ Media { title } }`, "its fields have to be selected"},
+		{`{ Media { id { value } } }`, "has no fields to select"},
+		{`{ Media { } }`, "nothing is selected"},
+		{`{ Media { id } } }`, "after the query"},
+		{`{ Media { id }`, "unexpected end"},
+		{`{ Media { ...fields } }`, "unexpected"},
+	}
+	for _, test := range invalid {
+		err := s.Validate(test.query)
+		if err == nil {
+			t.Errorf("Validate(%s) should have failed", test.query)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.mentions) {
+			t.Errorf("Validate(%s) = %q, should mention %q", test.query, err, test.mentions)
+		}
+	}
+}
+
+func TestParseArguments(t *testing.T) {
+	s, err := Parse(`
+type Query {
+  "A description."
+  Media(id: Int = 1, sort: [String] = ["a"], filter: Filter = {a: 1} @deprecated): String
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := s.ByName["Query"].Field("Media")
+	if !ok {
+		t.Fatal("no Media field")
+	}
+	var args []string
+	for _, arg := range f.Args {
+		args = append(args, arg.Name+": "+arg.Kind.String())
+	}
+	if got := strings.Join(args, ", "); got != "id: Int, sort: [String], filter: Filter" {
+		t.Errorf("got arguments %s", got)
+	}
+	if f.Description != "A description." || f.Kind.String() != "String" {
+		t.Errorf("got %+v", f)
+	}
+}
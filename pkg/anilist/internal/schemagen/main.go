@@ -0,0 +1,258 @@
+// Command schemagen turns a GraphQL schema into Go types: a string type with a constant per value
+// for each enum, and a struct for each object type. It only understands as much of the schema
+// definition language as the AniList schema uses, and skips input types, interfaces and unions.
+//
+// It's run by go generate in pkg/anilist:
+//
+//	go run ./internal/schemagen -schema schema.graphql -out schema_gen.go -package anilist -pointers Media.nextAiringEpisode
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist/internal/schema"
+)
+
+// scalars are the Go types GraphQL scalars are decoded into.
+var scalars = map[string]string{
+	"Int":          "int",
+	"Float":        "float64",
+	"String":       "string",
+	"Boolean":      "bool",
+	"ID":           "string",
+	"Json":         "json.RawMessage",
+	"CountryCode":  "string",
+	"FuzzyDateInt": "int",
+}
+
+// initialisms are written in capitals in Go names, as golint would like.
+var initialisms = map[string]bool{"id": true, "url": true, "tv": true, "ova": true, "ona": true}
+
+func main() {
+	schemaPath := flag.String("schema", "schema.graphql", "GraphQL schema to read")
+	out := flag.String("out", "schema_gen.go", "Go file to write")
+	pkg := flag.String("package", "anilist", "Package the Go file belongs to")
+	pointers := flag.String("pointers", "", "Comma separated Type.field list of object fields to make pointers, e.g. to break cycles")
+	flag.Parse()
+
+	source, err := ioutil.ReadFile(*schemaPath)
+	if err != nil {
+		fail(err)
+	}
+	s, err := schema.Parse(string(source))
+	if err != nil {
+		fail(fmt.Errorf("%s: %w", *schemaPath, err))
+	}
+
+	pointerFields := map[string]bool{}
+	for _, p := range strings.Split(*pointers, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			pointerFields[p] = true
+		}
+	}
+
+	code, err := generate(s, *pkg, *schemaPath, pointerFields)
+	if err != nil {
+		fail(err)
+	}
+	if err := ioutil.WriteFile(*out, code, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "schemagen:", err)
+	os.Exit(1)
+}
+
+// generate writes the Go source for every enum and object type in s.
+func generate(s *schema.Schema, pkg string, source string, pointers map[string]bool) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by schemagen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	usesJSON := false
+	for _, d := range s.Definitions {
+		for _, f := range d.Fields {
+			if f.Kind.Innermost().Name == "Json" {
+				usesJSON = true
+			}
+		}
+	}
+	if usesJSON {
+		b.WriteString("import \"encoding/json\"\n\n")
+	}
+
+	for _, d := range s.Definitions {
+		if d.Keyword == "enum" {
+			writeEnum(&b, d)
+		}
+	}
+	for _, d := range s.Definitions {
+		if d.Keyword == "type" {
+			if err := writeObject(&b, s, d, pointers); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, p := range sortedKeys(pointers) {
+		parts := strings.SplitN(p, ".", 2)
+		if len(parts) != 2 || !s.HasField(parts[0], parts[1]) {
+			return nil, fmt.Errorf("-pointers names %s, which isn't in the schema", p)
+		}
+	}
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return code, nil
+}
+
+func writeEnum(b *bytes.Buffer, d *schema.Definition) {
+	writeComment(b, "", fmt.Sprintf("%s is the AniList %s enum.", d.Name, d.Name), d.Description, "")
+	fmt.Fprintf(b, "type %s string\n\n", d.Name)
+
+	b.WriteString("const (\n")
+	for _, v := range d.Values {
+		writeComment(b, "\t", "", v.Description, v.Deprecated)
+		fmt.Fprintf(b, "\t%s%s %s = %q\n", d.Name, goName(v.Name), d.Name, v.Name)
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(b, "// %sValues lists every %s, in the order the schema declares them.\n", d.Name, d.Name)
+	fmt.Fprintf(b, "var %sValues = []%s{\n", d.Name, d.Name)
+	for _, v := range d.Values {
+		fmt.Fprintf(b, "\t%s%s,\n", d.Name, goName(v.Name))
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "func (e %s) String() string {\n\treturn string(e)\n}\n\n", d.Name)
+
+	fmt.Fprintf(b, "// IsValid reports whether e is one of the values the schema declares.\n")
+	fmt.Fprintf(b, "func (e %s) IsValid() bool {\n\tswitch e {\n\tcase ", d.Name)
+	for i, v := range d.Values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%s%s", d.Name, goName(v.Name))
+	}
+	b.WriteString(":\n\t\treturn true\n\t}\n\treturn false\n}\n\n")
+}
+
+func writeObject(b *bytes.Buffer, s *schema.Schema, d *schema.Definition, pointers map[string]bool) error {
+	writeComment(b, "", fmt.Sprintf("%s is the AniList %s object.", d.Name, d.Name), d.Description, "")
+	fmt.Fprintf(b, "type %s struct {\n", d.Name)
+	for _, f := range d.Fields {
+		goType, err := goType(s, f.Kind, pointers[d.Name+"."+f.Name])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", d.Name, f.Name, err)
+		}
+		writeComment(b, "\t", "", f.Description, f.Deprecated)
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`\n", goName(f.Name), goType, f.Name)
+	}
+	b.WriteString("}\n\n")
+	return nil
+}
+
+func writeComment(b *bytes.Buffer, indent string, summary string, description string, deprecated string) {
+	var lines []string
+	if summary != "" {
+		lines = append(lines, summary)
+	}
+	if description != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, strings.Split(strings.TrimSpace(description), "\n")...)
+	}
+	if deprecated != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "Deprecated: "+deprecated)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+// goType is the Go type a field of kind is decoded into. Object fields are embedded by value,
+// unless pointer is set, since a type can't contain itself.
+func goType(s *schema.Schema, kind schema.TypeRef, pointer bool) (string, error) {
+	if kind.List != nil {
+		element, err := goType(s, *kind.List, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + element, nil
+	}
+	if goType, ok := scalars[kind.Name]; ok {
+		return goType, nil
+	}
+	d, ok := s.ByName[kind.Name]
+	if !ok {
+		return "", fmt.Errorf("unknown type %s", kind.Name)
+	}
+	switch d.Keyword {
+	case "enum":
+		return d.Name, nil
+	case "type":
+		if pointer {
+			return "*" + d.Name, nil
+		}
+		return d.Name, nil
+	case "scalar":
+		return "", fmt.Errorf("scalar %s has no Go type, add it to schemagen's scalars", kind.Name)
+	}
+	return "", fmt.Errorf("%s %s can't be used as a field", d.Keyword, kind.Name)
+}
+
+// goName turns a GraphQL name like siteUrl or TV_SHORT into a Go one like SiteURL or TVShort.
+func goName(name string) string {
+	var words []string
+	if strings.ToUpper(name) == name {
+		words = strings.Split(strings.ToLower(name), "_")
+	} else {
+		start := 0
+		for i, r := range name {
+			if i > 0 && unicode.IsUpper(r) {
+				words = append(words, name[start:i])
+				start = i
+			}
+		}
+		words = append(words, name[start:])
+	}
+
+	var b strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// sortedKeys is used to make output independent of map ordering.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,178 @@
+package anilist
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TitleMatch is a candidate answer to a title search, along with how sure we are it's the one that was meant.
+type TitleMatch struct {
+	Media Media
+	// Confidence runs from 0, for a title with nothing in common with the search, to 1 for an exact match
+	// on the most popular candidate.
+	Confidence float64
+}
+
+// How much of a match's confidence comes from popularity, with the rest coming from its titles.
+const popularityWeight = 0.1
+
+// A search made of some of a title's words scores between partialMatchFloor and partialMatchCap, closer
+// to the cap the more of the title it covers. The cap keeps a partial match's confidence under 0.7, even
+// for the most popular candidate, so it's never mistaken for a close one.
+const (
+	partialMatchFloor = 0.4
+	partialMatchCap   = 0.65
+)
+
+// titleFolds spells out the characters that commonly differ between how a title is written and how it's typed.
+var titleFolds = strings.NewReplacer(
+	"×", "x",
+	"&", " and ",
+	"'", "", "’", "",
+	"ā", "a", "â", "a", "à", "a", "á", "a", "ä", "a",
+	"ē", "e", "ê", "e", "è", "e", "é", "e", "ë", "e",
+	"ī", "i", "î", "i", "ì", "i", "í", "i", "ï", "i",
+	"ō", "o", "ô", "o", "ò", "o", "ó", "o", "ö", "o",
+	"ū", "u", "û", "u", "ù", "u", "ú", "u", "ü", "u",
+)
+
+// normalizeTitle lowercases title, folds accents and punctuation away, and collapses whitespace,
+// so "Re:Zero" and "re zero" come out the same.
+func normalizeTitle(title string) string {
+	title = titleFolds.Replace(strings.ToLower(title))
+	return strings.Join(strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// titleSimilarity scores how alike two normalized titles are, from 0 to 1.
+func titleSimilarity(search string, title string) float64 {
+	if search == "" || title == "" {
+		return 0
+	}
+	if search == title {
+		return 1
+	}
+
+	a, b := []rune(search), []rune(title)
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	similarity := 1 - float64(levenshtein(a, b))/float64(longest)
+
+	// A search for some of the words in a title, like "bebop" for "cowboy bebop", is a decent match
+	// even though most of the characters differ. It's never a sure one though, since "one" is in plenty
+	// of titles besides "one piece", so it scores below partialMatchCap however many words it covers.
+	searchWords, titleWords := strings.Fields(search), strings.Fields(title)
+	words := map[string]bool{}
+	for _, word := range titleWords {
+		words[word] = true
+	}
+	found := 0
+	for _, word := range searchWords {
+		if words[word] {
+			found++
+		}
+	}
+	if found == len(searchWords) {
+		contained := partialMatchFloor + (partialMatchCap-partialMatchFloor)*float64(found)/float64(len(titleWords)+1)
+		if contained > similarity {
+			similarity = contained
+		}
+	}
+	return similarity
+}
+
+// levenshtein is the number of single character insertions, deletions or substitutions it takes to turn a into b.
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// bestTitleSimilarity is how closely the search matches any of media's titles, including synonyms.
+func bestTitleSimilarity(search string, media Media) float64 {
+	titles := append([]string{media.Title.Romaji, media.Title.English, media.Title.Native}, media.Synonyms...)
+	best := 0.0
+	for _, title := range titles {
+		if similarity := titleSimilarity(search, normalizeTitle(title)); similarity > best {
+			best = similarity
+		}
+	}
+	return best
+}
+
+// RankTitles orders medias by how well they answer a search for title, best first.
+// Every title, including native titles and synonyms, is compared after normalizing case, accents and punctuation,
+// and popularity breaks near ties between similar titles.
+func RankTitles(title string, medias []Media) []TitleMatch {
+	search := normalizeTitle(title)
+
+	mostPopular := 0
+	for _, media := range medias {
+		if media.Popularity > mostPopular {
+			mostPopular = media.Popularity
+		}
+	}
+
+	matches := make([]TitleMatch, 0, len(medias))
+	for _, media := range medias {
+		popularity := 0.0
+		if mostPopular > 0 {
+			popularity = math.Log1p(float64(media.Popularity)) / math.Log1p(float64(mostPopular))
+		}
+		matches = append(matches, TitleMatch{
+			Media:      media,
+			Confidence: (1-popularityWeight)*bestTitleSimilarity(search, media) + popularityWeight*popularity,
+		})
+	}
+
+	// Stable, so AniList's own ordering decides exact ties.
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+	return matches
+}
+
+// MatchTitle searches for media titled like query.Title and ranks what comes back with RankTitles.
+// query.PerPage is how many candidates to consider, 10 if it isn't set.
+func (c *Client) MatchTitle(ctx context.Context, query MediaQuery) ([]TitleMatch, error) {
+	query.Sort = []MediaSort{MediaSortSearchMatch}
+	if query.PerPage == 0 && query.MaxResults == 0 {
+		query.PerPage = 10
+	}
+
+	medias, err := c.MediaFromMediaQuery(ctx, query)
+	if err != nil {
+		return []TitleMatch{}, err
+	}
+	return RankTitles(query.Title, medias), nil
+}
+
+func MatchTitle(ctx context.Context, query MediaQuery) ([]TitleMatch, error) {
+	return DefaultClient.MatchTitle(ctx, query)
+}
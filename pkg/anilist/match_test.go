@@ -0,0 +1,171 @@
+package anilist
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Cowboy Bebop", "cowboy bebop"},
+		{"Re:Zero", "re zero"},
+		{"Steins;Gate", "steins gate"},
+		{"  Hunter   x  Hunter ", "hunter x hunter"},
+		{"Hunter×Hunter", "hunterxhunter"},
+		{"Kaguya-sama: Love is War", "kaguya sama love is war"},
+		{"Pokémon", "pokemon"},
+		{"Shōjo Kakumei Utena", "shojo kakumei utena"},
+		{"JoJo's Bizarre Adventure", "jojos bizarre adventure"},
+		{"Fruits Basket (2019)", "fruits basket 2019"},
+		{"Kill la Kill & More", "kill la kill and more"},
+		{"進撃の巨人", "進撃の巨人"},
+		{"", ""},
+		{"!!!", ""},
+	}
+	for _, test := range tests {
+		if got := normalizeTitle(test.title); got != test.want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", test.title, got, test.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "bebop", 5},
+		{"bebop", "", 5},
+		{"bebop", "bebop", 0},
+		{"bebop", "bepop", 1},
+		{"bebop", "bebo", 1},
+		{"bebop", "bebopp", 1},
+		{"bebpo", "bebop", 2},
+		{"kitten", "sitting", 3},
+		{"進撃", "進撃の巨人", 3},
+	}
+	for _, test := range tests {
+		if got := levenshtein([]rune(test.a), []rune(test.b)); got != test.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		search, title string
+		want          float64
+	}{
+		{"cowboy bebop", "cowboy bebop", 1},
+		{"", "cowboy bebop", 0},
+		{"cowboy bebop", "", 0},
+		// Typos count against a title character by character.
+		{"cowboy bebpo", "cowboy bebop", 1 - 2.0/12},
+		{"monstr", "monster", 1 - 1.0/7},
+		// Some of a title's words only make a partial match, closer to the cap the more of the title they cover.
+		{"bebop", "cowboy bebop", partialMatchFloor + (partialMatchCap-partialMatchFloor)/3},
+		{"one", "one piece", partialMatchFloor + (partialMatchCap-partialMatchFloor)/3},
+		{"berserk", "berserk ougon jidai hen i", partialMatchFloor + (partialMatchCap-partialMatchFloor)/6},
+		{"bebop cowboy", "cowboy bebop", partialMatchFloor + (partialMatchCap-partialMatchFloor)*2/3},
+		// A word that's missing from the title is no partial match.
+		{"zzz", "one piece", 0},
+	}
+	for _, test := range tests {
+		if got := titleSimilarity(test.search, test.title); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("titleSimilarity(%q, %q) = %f, want %f", test.search, test.title, got, test.want)
+		}
+	}
+}
+
+// titled is a media with the given romaji title and popularity.
+func titled(id int, romaji string, popularity int, synonyms ...string) Media {
+	var media Media
+	media.ID = id
+	media.Title.Romaji = romaji
+	media.Popularity = popularity
+	media.Synonyms = synonyms
+	return media
+}
+
+func TestRankTitles(t *testing.T) {
+	tests := []struct {
+		search string
+		medias []Media
+		// best is the ID of the media that should rank first.
+		best int
+		// confident is whether it should score at least 0.7, the bar the bot answers without asking at.
+		confident bool
+	}{
+		{
+			search:    "Cowboy Bebop",
+			medias:    []Media{titled(2, "Cowboy Bebop: Tengoku no Tobira", 80000), titled(1, "Cowboy Bebop", 300000)},
+			best:      1,
+			confident: true,
+		},
+		{
+			// The exact title wins, even over a more popular partial match.
+			search:    "Fullmetal Alchemist",
+			medias:    []Media{titled(2, "Fullmetal Alchemist: Brotherhood", 900000), titled(1, "Fullmetal Alchemist", 400000)},
+			best:      1,
+			confident: true,
+		},
+		{
+			search:    "bebop",
+			medias:    []Media{titled(1, "Cowboy Bebop", 300000)},
+			best:      1,
+			confident: false,
+		},
+		{
+			search:    "one",
+			medias:    []Media{titled(1, "ONE PIECE", 500000), titled(2, "One Outs", 20000)},
+			best:      1,
+			confident: false,
+		},
+		{
+			search:    "Berserk",
+			medias:    []Media{titled(1, "Berserk: Ougon Jidai-hen I", 60000)},
+			best:      1,
+			confident: false,
+		},
+		{
+			// Synonyms count as titles.
+			search:    "AoT",
+			medias:    []Media{titled(2, "Attack on Tomato", 10), titled(1, "Shingeki no Kyojin", 900000, "AoT")},
+			best:      1,
+			confident: true,
+		},
+		{
+			// Popularity breaks ties between equally good titles.
+			search:    "Hunter x Hunter",
+			medias:    []Media{titled(2, "Hunter x Hunter", 100000), titled(1, "Hunter × Hunter", 800000)},
+			best:      1,
+			confident: true,
+		},
+	}
+	for _, test := range tests {
+		matches := RankTitles(test.search, test.medias)
+		if len(matches) != len(test.medias) {
+			t.Errorf("%q: got %d matches for %d media", test.search, len(matches), len(test.medias))
+			continue
+		}
+		if matches[0].Media.ID != test.best {
+			t.Errorf("%q: ranked %q first", test.search, matches[0].Media.Title.Romaji)
+		}
+		if confident := matches[0].Confidence >= 0.7; confident != test.confident {
+			t.Errorf("%q: %q scored %f", test.search, matches[0].Media.Title.Romaji, matches[0].Confidence)
+		}
+		for i := 1; i < len(matches); i++ {
+			if matches[i].Confidence > matches[i-1].Confidence {
+				t.Errorf("%q: matches out of order", test.search)
+			}
+		}
+	}
+
+	if matches := RankTitles("bebop", nil); len(matches) != 0 {
+		t.Errorf("expected no matches for no media, got %v", matches)
+	}
+}
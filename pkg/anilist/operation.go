@@ -0,0 +1,286 @@
+package anilist
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// variable is a GraphQL variable an operation takes.
+type variable struct {
+	name string
+	// kind is the variable's GraphQL type, e.g. Int!, MediaType or [MediaSort].
+	kind string
+}
+
+// operation is a GraphQL query whose variables are declared once, alongside the body that uses them.
+// The query's variable definitions are written from the declarations, and every request is checked
+// against them before it's sent, so the two can't drift apart.
+type operation struct {
+	name      string
+	variables map[string]variable
+	// header is the query's variable definitions, in the order they were declared.
+	header string
+	// body is the query's selection set. If it contains %s, the Media fields asked for are selected there.
+	body string
+	// ttl caps how long the Client caches the operation's responses, for ones that go stale quickly.
+	// When it's 0, they're kept as long as the Client keeps everything else.
+	ttl time.Duration
+}
+
+var variableUse = regexp.MustCompile(`\$(\w+)`)
+
+// newOperation defines an operation, panicking if body uses a variable that isn't declared or
+// a declared variable is never used, so mistakes are caught as soon as the package is loaded.
+func newOperation(name string, body string, variables ...variable) *operation {
+	op := &operation{name: name, variables: map[string]variable{}, body: body}
+
+	definitions := make([]string, 0, len(variables))
+	for _, v := range variables {
+		if _, ok := op.variables[v.name]; ok {
+			panic(fmt.Sprintf("anilist: %s declares $%s twice", name, v.name))
+		}
+		op.variables[v.name] = v
+		definitions = append(definitions, fmt.Sprintf("$%s: %s", v.name, v.kind))
+	}
+	op.header = strings.Join(definitions, ", ")
+
+	used := map[string]bool{}
+	for _, match := range variableUse.FindAllStringSubmatch(body, -1) {
+		used[match[1]] = true
+		if _, ok := op.variables[match[1]]; !ok {
+			panic(fmt.Sprintf("anilist: %s uses undeclared variable $%s", name, match[1]))
+		}
+	}
+	for _, v := range variables {
+		if !used[v.name] {
+			panic(fmt.Sprintf("anilist: %s declares $%s but never uses it", name, v.name))
+		}
+	}
+	return op
+}
+
+// query is the operation's GraphQL document, selecting fields wherever the body asks for Media.
+func (op *operation) query(fields MediaFields) string {
+	body := op.body
+	if strings.Contains(body, "%s") {
+		body = fmt.Sprintf(body, fields.selection())
+	}
+	if op.header == "" {
+		return fmt.Sprintf("query %s { %s }", op.name, body)
+	}
+	return fmt.Sprintf("query %s(%s) { %s }", op.name, op.header, body)
+}
+
+// check makes sure vars only sets variables the operation declares, with values of the right kind,
+// and sets every variable the operation requires.
+func (op *operation) check(vars map[string]interface{}) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	// Sorted so the same mistake is always reported the same way.
+	sort.Strings(names)
+
+	for _, name := range names {
+		v, ok := op.variables[name]
+		if !ok {
+			return fmt.Errorf("%w: %s has no variable $%s", ErrInvalidQuery, op.name, name)
+		}
+		if !fits(v.kind, vars[name]) {
+			return fmt.Errorf("%w: %s expects $%s to be %s, not %T", ErrInvalidQuery, op.name, name, v.kind, vars[name])
+		}
+	}
+	for _, v := range op.variables {
+		if _, ok := vars[v.name]; !ok && strings.HasSuffix(v.kind, "!") {
+			return fmt.Errorf("%w: %s requires $%s", ErrInvalidQuery, op.name, v.name)
+		}
+	}
+	return nil
+}
+
+// fits reports whether value can be sent as a GraphQL variable of kind.
+func fits(kind string, value interface{}) bool {
+	kind = strings.TrimSuffix(kind, "!")
+	if value == nil {
+		return false
+	}
+	rv := reflect.ValueOf(value)
+
+	if strings.HasPrefix(kind, "[") {
+		if rv.Kind() != reflect.Slice {
+			return false
+		}
+		element := strings.TrimSuffix(strings.TrimPrefix(kind, "["), "]")
+		for i := 0; i < rv.Len(); i++ {
+			if !fits(element, rv.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch kind {
+	case "Int", "FuzzyDateInt":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return true
+		}
+		return false
+	case "Float":
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int64:
+			return true
+		}
+		return false
+	case "Boolean":
+		return rv.Kind() == reflect.Bool
+	default:
+		// Strings, IDs, country codes and AniList's enums are all sent as strings.
+		return rv.Kind() == reflect.String
+	}
+}
+
+// MediaFields picks which parts of a Media a lookup fills in, so callers that only need a title
+// don't pay for descriptions and credits. The zero value means AllMediaFields.
+//
+// ID, SiteURL, Title, Synonyms, MediaType, Format, IsAdult and Popularity are always included.
+type MediaFields uint
+
+const (
+	// MediaFieldBasic asks for only the fields that are always included.
+	MediaFieldBasic MediaFields = 1 << iota
+	MediaFieldDescription
+	MediaFieldCoverImage
+	// MediaFieldDetails is Source, Status, Episodes, Season, SeasonYear and AverageScore.
+	MediaFieldDetails
+	// MediaFieldDates is StartDate and EndDate.
+	MediaFieldDates
+	// MediaFieldAiring is NextAiringEpisode and AiringSchedule.
+	MediaFieldAiring
+	MediaFieldStudios
+	MediaFieldStaff
+	// MediaFieldCharacters is the main Characters, without their voice actors. CastFromMediaID has those.
+	MediaFieldCharacters
+
+	AllMediaFields = MediaFieldBasic | MediaFieldDescription | MediaFieldCoverImage | MediaFieldDetails |
+		MediaFieldDates | MediaFieldAiring | MediaFieldStudios | MediaFieldStaff | MediaFieldCharacters
+)
+
+// mediaSelections are the GraphQL selections behind each of MediaFields.
+var mediaSelections = []struct {
+	fields    MediaFields
+	selection string
+}{
+	{MediaFieldBasic, `
+      id
+      siteUrl
+      title {
+        english
+        romaji
+        native
+      }
+      synonyms
+      type
+      format
+      isAdult
+      popularity`},
+	{MediaFieldDescription, `
+      description(asHtml: false)`},
+	{MediaFieldCoverImage, `
+      coverImage {
+        extraLarge
+        large
+        medium
+      }`},
+	{MediaFieldDetails, `
+      source
+      status
+      episodes
+      season
+      seasonYear
+      averageScore`},
+	{MediaFieldDates, `
+      startDate {
+        year
+        month
+        day
+      }
+      endDate {
+        year
+        month
+        day
+      }`},
+	{MediaFieldAiring, `
+      nextAiringEpisode {
+        id
+        airingAt
+        episode
+      }
+      airingSchedule(notYetAired: true, perPage: 3) {
+        nodes {
+          id
+          airingAt
+          episode
+        }
+      }`},
+	{MediaFieldStudios, `
+      studios {
+        edges {
+          node {
+            id
+            name
+            siteUrl
+          }
+        }
+      }`},
+	{MediaFieldStaff, `
+      staff(sort: [RELEVANCE, ID], perPage: 25) {
+        edges {
+          role
+          node {
+            id
+            siteUrl
+            name {
+              first
+              last
+              full
+            }
+          }
+        }
+      }`},
+	{MediaFieldCharacters, `
+      characters(role: MAIN, sort: [ROLE, RELEVANCE, ID], perPage: 10) {
+        edges {
+          role
+          node {
+            id
+            siteUrl
+            name {
+              full
+            }
+          }
+        }
+      }`},
+}
+
+// selection is the GraphQL selection set for fields.
+func (fields MediaFields) selection() string {
+	if fields == 0 {
+		fields = AllMediaFields
+	}
+	// The basic fields are needed to make sense of anything else.
+	fields |= MediaFieldBasic
+
+	var b strings.Builder
+	for _, s := range mediaSelections {
+		if fields&s.fields != 0 {
+			b.WriteString(s.selection)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
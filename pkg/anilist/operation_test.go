@@ -0,0 +1,208 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// operations lists every operation the package sends, so none of them can be left out of the tests.
+func operations() []*operation {
+	return []*operation{
+		mediaIDQuery,
+		mediaTitleQuery,
+		mediaPersonQuery,
+		mediaStudioQuery,
+		staffSearchQuery,
+		studioSearchQuery,
+		mediaCastQuery,
+		characterQuery,
+		staffProfileQuery,
+		studioDetailQuery,
+		airingScheduleQuery,
+		seasonQuery,
+	}
+}
+
+func TestOperationsRenderForEveryMediaFields(t *testing.T) {
+	for _, op := range operations() {
+		for fields := MediaFields(0); fields <= AllMediaFields; fields++ {
+			query := op.query(fields)
+			if !strings.HasPrefix(query, "query "+op.name) {
+				t.Errorf("%s with fields %b: query starts %q", op.name, fields, query[:20])
+			}
+			if strings.Contains(query, "%!") {
+				t.Errorf("%s with fields %b: bad substitution in %s", op.name, fields, query)
+			}
+			for _, pair := range []string{"{}", "()", "[]"} {
+				if strings.Count(query, pair[:1]) != strings.Count(query, pair[1:]) {
+					t.Errorf("%s with fields %b: unbalanced %s in %s", op.name, fields, pair, query)
+				}
+			}
+		}
+	}
+}
+
+// recordingServer answers every request with empty data, and remembers the operations it was sent.
+func recordingServer(t *testing.T) (*Client, *[]string) {
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		sent = append(sent, strings.Fields(strings.SplitN(body.Query, "(", 2)[0])[1])
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(WithEndpoint(srv.URL)), &sent
+}
+
+// TestClientMethodsSendDeclaredVariables runs every lookup with as many of its options set as possible,
+// so any variable a method sets that its operation doesn't declare, or declares as another type, fails check.
+func TestClientMethodsSendDeclaredVariables(t *testing.T) {
+	c, sent := recordingServer(t)
+	ctx := context.Background()
+	notAdult := false
+	full := MediaQuery{
+		Title:           "bebop",
+		Type:            MediaTypeAnime,
+		Sort:            []MediaSort{MediaSortSearchMatch},
+		Genres:          []string{"Action"},
+		ExcludedGenres:  []string{"Horror"},
+		Tags:            []string{"Space"},
+		Formats:         []MediaFormat{MediaFormatTV, MediaFormatMovie},
+		Status:          MediaStatusFinished,
+		Season:          MediaSeasonSpring,
+		SeasonYear:      1998,
+		StartDateFrom:   FuzzyDate{Year: 1990},
+		StartDateTo:     FuzzyDate{Year: 2000, Month: 6},
+		MinScore:        70,
+		MaxScore:        90,
+		MinPopularity:   1000,
+		MaxPopularity:   500000,
+		IsAdult:         &notAdult,
+		CountryOfOrigin: "JP",
+		Source:          MediaSourceOriginal,
+		Page:            2,
+		PerPage:         5,
+	}
+
+	calls := []struct {
+		operation string
+		call      func() error
+	}{
+		{"MediaByID", func() error { _, err := c.MediaFromMediaID(ctx, 1); return err }},
+		{"MediaSearch", func() error { _, _, err := c.MediaPageFromMediaQuery(ctx, full); return err }},
+		{"MediaSearch", func() error { _, _, err := c.MediaPageFromMediaQuery(ctx, MediaQuery{ID: 1}); return err }},
+		{"MediaSearch", func() error { _, err := c.MatchTitle(ctx, MediaQuery{Title: "bebop"}); return err }},
+		{"StaffMedia", func() error {
+			_, _, err := c.MediaPageFromPersonQuery(ctx, PersonQuery{Name: "watanabe", Type: MediaTypeAnime, Page: 2, PerPage: 5})
+			return err
+		}},
+		{"StaffMedia", func() error { _, err := c.MediaFromPersonID(ctx, 1, 5); return err }},
+		{"StudioMedia", func() error {
+			_, _, err := c.MediaPageFromStudioQuery(ctx, StudioQuery{Name: "sunrise", Page: 2})
+			return err
+		}},
+		{"StudioMedia", func() error { _, err := c.MediaFromStudioID(ctx, 1, 5); return err }},
+		{"StaffSearch", func() error { _, err := c.PeopleFromName(ctx, "watanabe", 5); return err }},
+		{"StudioSearch", func() error { _, err := c.StudiosFromName(ctx, "sunrise", 5); return err }},
+		{"MediaCast", func() error { _, _, err := c.CastFromMediaID(ctx, 1, 0); return err }},
+		{"Characters", func() error { _, err := c.CharactersFromName(ctx, "spike", 1); return err }},
+		{"Characters", func() error {
+			_, err := c.CharactersFromCharacterQuery(ctx, CharacterQuery{ID: 1, MaxResults: 1, Appearances: 3})
+			return err
+		}},
+		{"StaffProfiles", func() error {
+			_, err := c.StaffFromPersonQuery(ctx, PersonQuery{Name: "watanabe", Type: MediaTypeManga, PerPage: 1})
+			return err
+		}},
+		{"StudioDetail", func() error {
+			_, err := c.StudioDetailFromStudioQuery(ctx, StudioQuery{ID: 1, Page: 2, PerPage: 5})
+			return err
+		}},
+		{"AiringSchedules", func() error {
+			_, err := c.AiringSchedulesFromAiringQuery(ctx, AiringQuery{From: time.Now(), To: time.Now().Add(time.Hour), MediaIDs: []int{1, 2}})
+			return err
+		}},
+		{"Season", func() error { _, _, err := c.MediaFromSeason(ctx, MediaSeasonFall, 2020, &notAdult, 1, 10); return err }},
+	}
+
+	for _, call := range calls {
+		*sent = nil
+		if err := call.call(); err != nil {
+			t.Errorf("%s: %v", call.operation, err)
+			continue
+		}
+		if len(*sent) == 0 || (*sent)[0] != call.operation {
+			t.Errorf("expected %s to be sent, got %v", call.operation, *sent)
+		}
+	}
+}
+
+func TestCheckRejectsBadVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]interface{}
+	}{
+		{"undeclared", map[string]interface{}{"page": 1, "max": 1, "title": "bebop"}},
+		{"wrong type", map[string]interface{}{"page": "1", "max": 1}},
+		{"wrong list element", map[string]interface{}{"page": 1, "max": 1, "genres": []int{1}}},
+		{"list for scalar", map[string]interface{}{"page": 1, "max": 1, "search": []string{"bebop"}}},
+		{"nil", map[string]interface{}{"page": 1, "max": 1, "search": nil}},
+		{"missing required", map[string]interface{}{"page": 1}},
+	}
+	for _, test := range tests {
+		err := mediaTitleQuery.check(test.vars)
+		if !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected ErrInvalidQuery, got %v", test.name, err)
+		}
+	}
+
+	if err := mediaTitleQuery.check(map[string]interface{}{"page": 1, "max": 1, "search": "bebop"}); err != nil {
+		t.Errorf("valid variables: %v", err)
+	}
+}
+
+// TestRunOperationChecksBeforeSending makes sure a bad variable never reaches the endpoint.
+func TestRunOperationChecksBeforeSending(t *testing.T) {
+	c, sent := recordingServer(t)
+	var res Query
+	err := c.runOperation(context.Background(), mediaIDQuery, 0, map[string]interface{}{"id": 1, "idMal": 1}, &res)
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	if len(*sent) != 0 {
+		t.Errorf("expected nothing to be sent, got %v", *sent)
+	}
+}
+
+func TestNewOperationPanicsOnMismatchedDeclarations(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		variables []variable
+	}{
+		{"undeclared", `Media(id: $id) { id }`, nil},
+		{"unused", `Media { id }`, []variable{{"id", "Int"}}},
+		{"duplicate", `Media(id: $id) { id }`, []variable{{"id", "Int"}, {"id", "Int"}}},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic", test.name)
+				}
+			}()
+			newOperation("Test", test.body, test.variables...)
+		}()
+	}
+}
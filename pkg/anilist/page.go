@@ -0,0 +1,143 @@
+package anilist
+
+import (
+	"context"
+)
+
+// pageVars sets the page variables used by every paginated query. Pages are numbered from 1,
+// and page 0 is taken to mean the first.
+func pageVars(vars map[string]interface{}, page int, perPage int) map[string]interface{} {
+	if page < 1 {
+		page = 1
+	}
+	vars["page"] = page
+	vars["max"] = perPage
+	return vars
+}
+
+// perPage picks between PerPage and the older MaxResults, preferring PerPage when both are set.
+func perPage(perPage int, maxResults int) int {
+	if perPage != 0 {
+		return perPage
+	}
+	return maxResults
+}
+
+// mediaPageFunc fetches one page of media.
+type mediaPageFunc func(ctx context.Context, page int) ([]Media, PageInfo, error)
+
+// MediaIterator walks through every page of a query's results, only fetching a page once the
+// previous one has been used up. Use it like a bufio.Scanner:
+//
+//	it := client.IterateMedia(query)
+//	for it.Next(ctx) {
+//		media := it.Media()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type MediaIterator struct {
+	fetch mediaPageFunc
+	page  int
+
+	medias   []Media
+	index    int
+	pageInfo PageInfo
+	done     bool
+	err      error
+}
+
+func newMediaIterator(first int, fetch mediaPageFunc) *MediaIterator {
+	if first < 1 {
+		first = 1
+	}
+	return &MediaIterator{fetch: fetch, page: first, index: -1}
+}
+
+// Next advances to the next Media, fetching another page if needed. It returns false once the
+// results run out, a page fails to load, or ctx is done; check Err to tell which.
+func (it *MediaIterator) Next(ctx context.Context) bool {
+	if it.done {
+		return false
+	}
+	if it.index+1 < len(it.medias) {
+		it.index++
+		return true
+	}
+	if it.medias != nil && !it.pageInfo.HasNextPage {
+		it.done = true
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+
+	medias, pageInfo, err := it.fetch(ctx, it.page)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	it.page++
+	it.medias = medias
+	it.pageInfo = pageInfo
+	it.index = 0
+	if len(medias) == 0 {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Media is the current result. It is only valid after a call to Next returns true.
+func (it *MediaIterator) Media() Media {
+	return it.medias[it.index]
+}
+
+// PageInfo describes the page the current result came from.
+func (it *MediaIterator) PageInfo() PageInfo {
+	return it.pageInfo
+}
+
+// Err is the error that stopped iteration, or nil if the results simply ran out.
+func (it *MediaIterator) Err() error {
+	return it.err
+}
+
+// IterateMedia walks every page of media matching query, starting from query.Page.
+func (c *Client) IterateMedia(query MediaQuery) *MediaIterator {
+	return newMediaIterator(query.Page, func(ctx context.Context, page int) ([]Media, PageInfo, error) {
+		query.Page = page
+		return c.MediaPageFromMediaQuery(ctx, query)
+	})
+}
+
+// IteratePersonMedia walks every page of media credited to the person matching query, starting from query.Page.
+func (c *Client) IteratePersonMedia(query PersonQuery) *MediaIterator {
+	return newMediaIterator(query.Page, func(ctx context.Context, page int) ([]Media, PageInfo, error) {
+		query.Page = page
+		return c.MediaPageFromPersonQuery(ctx, query)
+	})
+}
+
+// IterateStudioMedia walks every page of media made by the studio matching query, starting from query.Page.
+func (c *Client) IterateStudioMedia(query StudioQuery) *MediaIterator {
+	return newMediaIterator(query.Page, func(ctx context.Context, page int) ([]Media, PageInfo, error) {
+		query.Page = page
+		return c.MediaPageFromStudioQuery(ctx, query)
+	})
+}
+
+func IterateMedia(query MediaQuery) *MediaIterator {
+	return DefaultClient.IterateMedia(query)
+}
+
+func IteratePersonMedia(query PersonQuery) *MediaIterator {
+	return DefaultClient.IteratePersonMedia(query)
+}
+
+func IterateStudioMedia(query StudioQuery) *MediaIterator {
+	return DefaultClient.IterateStudioMedia(query)
+}
@@ -0,0 +1,201 @@
+package anilist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pagedServer stands in for AniList, answering searches with pages of media numbered from 1, perPage to a page,
+// until there are total of them. hasNext overrides whether a page says another follows it, if set.
+// It records the pages asked for.
+func pagedServer(t *testing.T, total int, perPage int, hasNext func(page int) bool) (*Client, *[]int) {
+	var asked []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				Page int `json:"page"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		page := body.Variables.Page
+		asked = append(asked, page)
+
+		var medias []string
+		for id := (page-1)*perPage + 1; id <= page*perPage && id <= total; id++ {
+			medias = append(medias, fmt.Sprintf(`{"id":%d}`, id))
+		}
+		lastPage := (total + perPage - 1) / perPage
+		next := page < lastPage
+		if hasNext != nil {
+			next = hasNext(page)
+		}
+		fmt.Fprintf(w, `{"data":{"Page":{"pageInfo":{"total":%d,"perPage":%d,"currentPage":%d,"lastPage":%d,"hasNextPage":%t},"media":[%s]}}}`,
+			total, perPage, page, lastPage, next, strings.Join(medias, ","))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(WithEndpoint(srv.URL)), &asked
+}
+
+// drain runs it to the end, returning the IDs of the media it went through.
+func drain(ctx context.Context, it *MediaIterator) []int {
+	ids := []int{}
+	for it.Next(ctx) {
+		ids = append(ids, it.Media().ID)
+	}
+	return ids
+}
+
+func TestMediaIterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		first   int
+		hasNext func(page int) bool
+		want    []int
+		asked   []int
+	}{
+		{
+			name:  "stops after the last page without asking for another",
+			total: 5,
+			want:  []int{1, 2, 3, 4, 5},
+			asked: []int{1, 2, 3},
+		},
+		{
+			name:  "a full last page",
+			total: 4,
+			want:  []int{1, 2, 3, 4},
+			asked: []int{1, 2},
+		},
+		{
+			name:  "starts from the query's page",
+			total: 5,
+			first: 2,
+			want:  []int{3, 4, 5},
+			asked: []int{2, 3},
+		},
+		{
+			name:  "no results",
+			total: 0,
+			want:  []int{},
+			asked: []int{1},
+		},
+		{
+			// AniList's counts are estimates, so a page can claim there's more when there isn't.
+			name:    "stops at an empty page",
+			total:   3,
+			hasNext: func(page int) bool { return true },
+			want:    []int{1, 2, 3},
+			asked:   []int{1, 2, 3},
+		},
+	}
+	for _, test := range tests {
+		c, asked := pagedServer(t, test.total, 2, test.hasNext)
+		it := c.IterateMedia(MediaQuery{Title: "bebop", Page: test.first, PerPage: 2})
+		if got := drain(context.Background(), it); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: went through %v, want %v", test.name, got, test.want)
+		}
+		if !reflect.DeepEqual(*asked, test.asked) {
+			t.Errorf("%s: asked for pages %v, want %v", test.name, *asked, test.asked)
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		// Once it's done, it stays done.
+		if it.Next(context.Background()) {
+			t.Errorf("%s: Next returned true after the end", test.name)
+		}
+	}
+}
+
+func TestMediaIteratorPageInfo(t *testing.T) {
+	c, _ := pagedServer(t, 3, 2, nil)
+	it := c.IterateMedia(MediaQuery{PerPage: 2})
+	for it.Next(context.Background()) {
+		want := (it.Media().ID + 1) / 2
+		if page := it.PageInfo().CurrentPage; page != want {
+			t.Errorf("media %d: PageInfo says page %d, want %d", it.Media().ID, page, want)
+		}
+	}
+}
+
+func TestMediaIteratorErrors(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Write([]byte(`{"data":{"Page":{"pageInfo":{"currentPage":1,"hasNextPage":true},"media":[{"id":1}]}}}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"errors":[{"message":"Internal Server Error","status":500}]}`))
+	}))
+	defer srv.Close()
+
+	it := NewClient(WithEndpoint(srv.URL)).IterateMedia(MediaQuery{PerPage: 1})
+	if got := drain(context.Background(), it); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("went through %v before the error", got)
+	}
+	if !errors.Is(it.Err(), ErrUpstream) {
+		t.Errorf("expected ErrUpstream, got %v", it.Err())
+	}
+	if it.Next(context.Background()) || requests != 2 {
+		t.Errorf("expected it to stop at the error, made %d requests", requests)
+	}
+}
+
+func TestMediaIteratorCancelled(t *testing.T) {
+	c, asked := pagedServer(t, 10, 2, nil)
+	it := c.IterateMedia(MediaQuery{PerPage: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []int
+	for it.Next(ctx) {
+		got = append(got, it.Media().ID)
+		if len(got) == 1 {
+			cancel()
+		}
+	}
+	// The page already fetched is still gone through, but no more are asked for.
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("went through %v", got)
+	}
+	if !reflect.DeepEqual(*asked, []int{1}) {
+		t.Errorf("asked for pages %v after being cancelled", *asked)
+	}
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", it.Err())
+	}
+
+	// A context that's done before the first page stops it straight away.
+	c, asked = pagedServer(t, 10, 2, nil)
+	it = c.IterateMedia(MediaQuery{PerPage: 2})
+	if it.Next(ctx) || len(*asked) != 0 || !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expected nothing to be fetched with a cancelled context, asked for %v, got %v", *asked, it.Err())
+	}
+}
+
+func TestPageVars(t *testing.T) {
+	tests := []struct {
+		page, perPage int
+		want          map[string]interface{}
+	}{
+		{0, 10, map[string]interface{}{"page": 1, "max": 10}},
+		{-1, 10, map[string]interface{}{"page": 1, "max": 10}},
+		{3, 25, map[string]interface{}{"page": 3, "max": 25}},
+	}
+	for _, test := range tests {
+		if got := pageVars(map[string]interface{}{}, test.page, test.perPage); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("pageVars(%d, %d) = %v, want %v", test.page, test.perPage, got, test.want)
+		}
+	}
+}
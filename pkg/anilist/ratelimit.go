@@ -0,0 +1,204 @@
+package anilist
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// AniList budgets requests over a rolling one minute window.
+	rateLimitWindow = time.Minute
+	// Once the remaining budget drops to this many requests, outgoing requests are spread
+	// out over the rest of the window instead of being sent immediately.
+	rateLimitLowWater = 5
+
+	defaultMaxRetries    = 2
+	defaultMaxRetryAfter = 10 * time.Second
+)
+
+// RateLimitError is returned when AniList refuses a request because we have exhausted our
+// request budget, and waiting it out would take longer than the Client is willing to block.
+type RateLimitError struct {
+	// RetryAfter is how long AniList asked us to wait before trying again.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("anilist: rate limited, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// RateLimitStatus is the last request budget reported by AniList.
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	// Reset is when requests will be accepted again, zero unless we are currently throttled.
+	Reset time.Time
+}
+
+// WithRetries sets how many times a throttled request is retried, and the longest Retry-After
+// the Client will sleep through before giving up and returning a *RateLimitError.
+func WithRetries(maxRetries int, maxRetryAfter time.Duration) ClientOption {
+	return func(c *Client) {
+		c.limiter.maxRetries = maxRetries
+		c.limiter.maxRetryAfter = maxRetryAfter
+	}
+}
+
+// RateLimit reports the request budget AniList gave us on the most recent response.
+// Limit and Remaining are -1 until the first response has been seen.
+func (c *Client) RateLimit() RateLimitStatus {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	status := RateLimitStatus{
+		Limit:     c.limiter.limit,
+		Remaining: c.limiter.remaining,
+	}
+	if time.Now().Before(c.limiter.blockedUntil) {
+		status.Reset = c.limiter.blockedUntil
+	}
+	return status
+}
+
+type rateLimiter struct {
+	mu sync.Mutex
+
+	limit        int
+	remaining    int
+	blockedUntil time.Time
+	next         time.Time
+
+	maxRetries    int
+	maxRetryAfter time.Duration
+
+	rand *rand.Rand
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		limit:         -1,
+		remaining:     -1,
+		maxRetries:    defaultMaxRetries,
+		maxRetryAfter: defaultMaxRetryAfter,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// reserve returns how long the caller should wait before sending its request.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.remaining < 0 || l.remaining > rateLimitLowWater || l.limit <= 0 {
+		return 0
+	}
+
+	// Running low, hand out evenly spaced slots for the rest of the window.
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(rateLimitWindow / time.Duration(l.limit))
+	return delay
+}
+
+func (l *rateLimiter) update(res *http.Response, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit, err := strconv.Atoi(res.Header.Get("X-RateLimit-Limit")); err == nil {
+		l.limit = limit
+	}
+	if remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining")); err == nil {
+		l.remaining = remaining
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		l.remaining = 0
+		l.blockedUntil = now.Add(retryAfter(res, now))
+	}
+}
+
+// jitter spreads retries from concurrent callers so they don't all land at the same instant.
+func (l *rateLimiter) jitter(d time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return d + time.Duration(l.rand.Int63n(int64(time.Second)))
+}
+
+// retryAfter reads how long AniList wants us to back off from a 429 response.
+func retryAfter(res *http.Response, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if d := time.Unix(reset, 0).Sub(now); d > 0 {
+			return d
+		}
+	}
+	return rateLimitWindow
+}
+
+// rateLimitTransport delays requests while the budget is low and retries throttled ones.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		// Don't queue behind a backoff longer than we would be willing to retry through.
+		delay := t.limiter.reserve(time.Now())
+		if delay > t.limiter.maxRetryAfter {
+			return nil, &RateLimitError{RetryAfter: delay}
+		}
+		if err := sleep(req, delay); err != nil {
+			return nil, err
+		}
+
+		res, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.limiter.update(res, time.Now())
+		if res.StatusCode != http.StatusTooManyRequests {
+			return res, nil
+		}
+		res.Body.Close()
+
+		wait := retryAfter(res, time.Now())
+		if attempt >= t.limiter.maxRetries || wait > t.limiter.maxRetryAfter || req.GetBody == nil {
+			return nil, &RateLimitError{RetryAfter: wait}
+		}
+		if err := sleep(req, t.limiter.jitter(wait)); err != nil {
+			return nil, err
+		}
+
+		// The previous attempt consumed the body, get a fresh copy for the retry.
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+}
+
+func sleep(req *http.Request, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
@@ -0,0 +1,743 @@
+// Code generated by schemagen from schema.graphql. DO NOT EDIT.
+
+package anilist
+
+// MediaType is the AniList MediaType enum.
+type MediaType string
+
+const (
+	MediaTypeAnime MediaType = "ANIME"
+	MediaTypeManga MediaType = "MANGA"
+)
+
+// MediaTypeValues lists every MediaType, in the order the schema declares them.
+var MediaTypeValues = []MediaType{
+	MediaTypeAnime,
+	MediaTypeManga,
+}
+
+func (e MediaType) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e MediaType) IsValid() bool {
+	switch e {
+	case MediaTypeAnime, MediaTypeManga:
+		return true
+	}
+	return false
+}
+
+// MediaFormat is the AniList MediaFormat enum.
+type MediaFormat string
+
+const (
+	MediaFormatTV      MediaFormat = "TV"
+	MediaFormatTVShort MediaFormat = "TV_SHORT"
+	MediaFormatMovie   MediaFormat = "MOVIE"
+	MediaFormatSpecial MediaFormat = "SPECIAL"
+	MediaFormatOVA     MediaFormat = "OVA"
+	MediaFormatONA     MediaFormat = "ONA"
+	MediaFormatMusic   MediaFormat = "MUSIC"
+	MediaFormatManga   MediaFormat = "MANGA"
+	MediaFormatNovel   MediaFormat = "NOVEL"
+	MediaFormatOneShot MediaFormat = "ONE_SHOT"
+)
+
+// MediaFormatValues lists every MediaFormat, in the order the schema declares them.
+var MediaFormatValues = []MediaFormat{
+	MediaFormatTV,
+	MediaFormatTVShort,
+	MediaFormatMovie,
+	MediaFormatSpecial,
+	MediaFormatOVA,
+	MediaFormatONA,
+	MediaFormatMusic,
+	MediaFormatManga,
+	MediaFormatNovel,
+	MediaFormatOneShot,
+}
+
+func (e MediaFormat) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e MediaFormat) IsValid() bool {
+	switch e {
+	case MediaFormatTV, MediaFormatTVShort, MediaFormatMovie, MediaFormatSpecial, MediaFormatOVA, MediaFormatONA, MediaFormatMusic, MediaFormatManga, MediaFormatNovel, MediaFormatOneShot:
+		return true
+	}
+	return false
+}
+
+// MediaStatus is the AniList MediaStatus enum.
+type MediaStatus string
+
+const (
+	MediaStatusFinished       MediaStatus = "FINISHED"
+	MediaStatusReleasing      MediaStatus = "RELEASING"
+	MediaStatusNotYetReleased MediaStatus = "NOT_YET_RELEASED"
+	MediaStatusCancelled      MediaStatus = "CANCELLED"
+	MediaStatusHiatus         MediaStatus = "HIATUS"
+)
+
+// MediaStatusValues lists every MediaStatus, in the order the schema declares them.
+var MediaStatusValues = []MediaStatus{
+	MediaStatusFinished,
+	MediaStatusReleasing,
+	MediaStatusNotYetReleased,
+	MediaStatusCancelled,
+	MediaStatusHiatus,
+}
+
+func (e MediaStatus) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e MediaStatus) IsValid() bool {
+	switch e {
+	case MediaStatusFinished, MediaStatusReleasing, MediaStatusNotYetReleased, MediaStatusCancelled, MediaStatusHiatus:
+		return true
+	}
+	return false
+}
+
+// MediaSeason is the AniList MediaSeason enum.
+type MediaSeason string
+
+const (
+	MediaSeasonWinter MediaSeason = "WINTER"
+	MediaSeasonSpring MediaSeason = "SPRING"
+	MediaSeasonSummer MediaSeason = "SUMMER"
+	MediaSeasonFall   MediaSeason = "FALL"
+)
+
+// MediaSeasonValues lists every MediaSeason, in the order the schema declares them.
+var MediaSeasonValues = []MediaSeason{
+	MediaSeasonWinter,
+	MediaSeasonSpring,
+	MediaSeasonSummer,
+	MediaSeasonFall,
+}
+
+func (e MediaSeason) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e MediaSeason) IsValid() bool {
+	switch e {
+	case MediaSeasonWinter, MediaSeasonSpring, MediaSeasonSummer, MediaSeasonFall:
+		return true
+	}
+	return false
+}
+
+// MediaSource is the AniList MediaSource enum.
+type MediaSource string
+
+const (
+	MediaSourceOriginal          MediaSource = "ORIGINAL"
+	MediaSourceManga             MediaSource = "MANGA"
+	MediaSourceLightNovel        MediaSource = "LIGHT_NOVEL"
+	MediaSourceVisualNovel       MediaSource = "VISUAL_NOVEL"
+	MediaSourceVideoGame         MediaSource = "VIDEO_GAME"
+	MediaSourceOther             MediaSource = "OTHER"
+	MediaSourceNovel             MediaSource = "NOVEL"
+	MediaSourceDoujinshi         MediaSource = "DOUJINSHI"
+	MediaSourceAnime             MediaSource = "ANIME"
+	MediaSourceWebNovel          MediaSource = "WEB_NOVEL"
+	MediaSourceLiveAction        MediaSource = "LIVE_ACTION"
+	MediaSourceGame              MediaSource = "GAME"
+	MediaSourceComic             MediaSource = "COMIC"
+	MediaSourceMultimediaProject MediaSource = "MULTIMEDIA_PROJECT"
+	MediaSourcePictureBook       MediaSource = "PICTURE_BOOK"
+)
+
+// MediaSourceValues lists every MediaSource, in the order the schema declares them.
+var MediaSourceValues = []MediaSource{
+	MediaSourceOriginal,
+	MediaSourceManga,
+	MediaSourceLightNovel,
+	MediaSourceVisualNovel,
+	MediaSourceVideoGame,
+	MediaSourceOther,
+	MediaSourceNovel,
+	MediaSourceDoujinshi,
+	MediaSourceAnime,
+	MediaSourceWebNovel,
+	MediaSourceLiveAction,
+	MediaSourceGame,
+	MediaSourceComic,
+	MediaSourceMultimediaProject,
+	MediaSourcePictureBook,
+}
+
+func (e MediaSource) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e MediaSource) IsValid() bool {
+	switch e {
+	case MediaSourceOriginal, MediaSourceManga, MediaSourceLightNovel, MediaSourceVisualNovel, MediaSourceVideoGame, MediaSourceOther, MediaSourceNovel, MediaSourceDoujinshi, MediaSourceAnime, MediaSourceWebNovel, MediaSourceLiveAction, MediaSourceGame, MediaSourceComic, MediaSourceMultimediaProject, MediaSourcePictureBook:
+		return true
+	}
+	return false
+}
+
+// MediaSort is the AniList MediaSort enum.
+type MediaSort string
+
+const (
+	MediaSortID               MediaSort = "ID"
+	MediaSortIDDesc           MediaSort = "ID_DESC"
+	MediaSortTitleRomaji      MediaSort = "TITLE_ROMAJI"
+	MediaSortTitleRomajiDesc  MediaSort = "TITLE_ROMAJI_DESC"
+	MediaSortTitleEnglish     MediaSort = "TITLE_ENGLISH"
+	MediaSortTitleEnglishDesc MediaSort = "TITLE_ENGLISH_DESC"
+	MediaSortTitleNative      MediaSort = "TITLE_NATIVE"
+	MediaSortTitleNativeDesc  MediaSort = "TITLE_NATIVE_DESC"
+	MediaSortType             MediaSort = "TYPE"
+	MediaSortTypeDesc         MediaSort = "TYPE_DESC"
+	MediaSortFormat           MediaSort = "FORMAT"
+	MediaSortFormatDesc       MediaSort = "FORMAT_DESC"
+	MediaSortStartDate        MediaSort = "START_DATE"
+	MediaSortStartDateDesc    MediaSort = "START_DATE_DESC"
+	MediaSortEndDate          MediaSort = "END_DATE"
+	MediaSortEndDateDesc      MediaSort = "END_DATE_DESC"
+	MediaSortScore            MediaSort = "SCORE"
+	MediaSortScoreDesc        MediaSort = "SCORE_DESC"
+	MediaSortPopularity       MediaSort = "POPULARITY"
+	MediaSortPopularityDesc   MediaSort = "POPULARITY_DESC"
+	MediaSortTrending         MediaSort = "TRENDING"
+	MediaSortTrendingDesc     MediaSort = "TRENDING_DESC"
+	MediaSortEpisodes         MediaSort = "EPISODES"
+	MediaSortEpisodesDesc     MediaSort = "EPISODES_DESC"
+	MediaSortDuration         MediaSort = "DURATION"
+	MediaSortDurationDesc     MediaSort = "DURATION_DESC"
+	MediaSortStatus           MediaSort = "STATUS"
+	MediaSortStatusDesc       MediaSort = "STATUS_DESC"
+	MediaSortChapters         MediaSort = "CHAPTERS"
+	MediaSortChaptersDesc     MediaSort = "CHAPTERS_DESC"
+	MediaSortVolumes          MediaSort = "VOLUMES"
+	MediaSortVolumesDesc      MediaSort = "VOLUMES_DESC"
+	MediaSortUpdatedAt        MediaSort = "UPDATED_AT"
+	MediaSortUpdatedAtDesc    MediaSort = "UPDATED_AT_DESC"
+	MediaSortSearchMatch      MediaSort = "SEARCH_MATCH"
+	MediaSortFavourites       MediaSort = "FAVOURITES"
+	MediaSortFavouritesDesc   MediaSort = "FAVOURITES_DESC"
+)
+
+// MediaSortValues lists every MediaSort, in the order the schema declares them.
+var MediaSortValues = []MediaSort{
+	MediaSortID,
+	MediaSortIDDesc,
+	MediaSortTitleRomaji,
+	MediaSortTitleRomajiDesc,
+	MediaSortTitleEnglish,
+	MediaSortTitleEnglishDesc,
+	MediaSortTitleNative,
+	MediaSortTitleNativeDesc,
+	MediaSortType,
+	MediaSortTypeDesc,
+	MediaSortFormat,
+	MediaSortFormatDesc,
+	MediaSortStartDate,
+	MediaSortStartDateDesc,
+	MediaSortEndDate,
+	MediaSortEndDateDesc,
+	MediaSortScore,
+	MediaSortScoreDesc,
+	MediaSortPopularity,
+	MediaSortPopularityDesc,
+	MediaSortTrending,
+	MediaSortTrendingDesc,
+	MediaSortEpisodes,
+	MediaSortEpisodesDesc,
+	MediaSortDuration,
+	MediaSortDurationDesc,
+	MediaSortStatus,
+	MediaSortStatusDesc,
+	MediaSortChapters,
+	MediaSortChaptersDesc,
+	MediaSortVolumes,
+	MediaSortVolumesDesc,
+	MediaSortUpdatedAt,
+	MediaSortUpdatedAtDesc,
+	MediaSortSearchMatch,
+	MediaSortFavourites,
+	MediaSortFavouritesDesc,
+}
+
+func (e MediaSort) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e MediaSort) IsValid() bool {
+	switch e {
+	case MediaSortID, MediaSortIDDesc, MediaSortTitleRomaji, MediaSortTitleRomajiDesc, MediaSortTitleEnglish, MediaSortTitleEnglishDesc, MediaSortTitleNative, MediaSortTitleNativeDesc, MediaSortType, MediaSortTypeDesc, MediaSortFormat, MediaSortFormatDesc, MediaSortStartDate, MediaSortStartDateDesc, MediaSortEndDate, MediaSortEndDateDesc, MediaSortScore, MediaSortScoreDesc, MediaSortPopularity, MediaSortPopularityDesc, MediaSortTrending, MediaSortTrendingDesc, MediaSortEpisodes, MediaSortEpisodesDesc, MediaSortDuration, MediaSortDurationDesc, MediaSortStatus, MediaSortStatusDesc, MediaSortChapters, MediaSortChaptersDesc, MediaSortVolumes, MediaSortVolumesDesc, MediaSortUpdatedAt, MediaSortUpdatedAtDesc, MediaSortSearchMatch, MediaSortFavourites, MediaSortFavouritesDesc:
+		return true
+	}
+	return false
+}
+
+// CharacterRole is the AniList CharacterRole enum.
+type CharacterRole string
+
+const (
+	CharacterRoleMain       CharacterRole = "MAIN"
+	CharacterRoleSupporting CharacterRole = "SUPPORTING"
+	CharacterRoleBackground CharacterRole = "BACKGROUND"
+)
+
+// CharacterRoleValues lists every CharacterRole, in the order the schema declares them.
+var CharacterRoleValues = []CharacterRole{
+	CharacterRoleMain,
+	CharacterRoleSupporting,
+	CharacterRoleBackground,
+}
+
+func (e CharacterRole) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e CharacterRole) IsValid() bool {
+	switch e {
+	case CharacterRoleMain, CharacterRoleSupporting, CharacterRoleBackground:
+		return true
+	}
+	return false
+}
+
+// CharacterSort is the AniList CharacterSort enum.
+type CharacterSort string
+
+const (
+	CharacterSortID             CharacterSort = "ID"
+	CharacterSortIDDesc         CharacterSort = "ID_DESC"
+	CharacterSortRole           CharacterSort = "ROLE"
+	CharacterSortRoleDesc       CharacterSort = "ROLE_DESC"
+	CharacterSortSearchMatch    CharacterSort = "SEARCH_MATCH"
+	CharacterSortFavourites     CharacterSort = "FAVOURITES"
+	CharacterSortFavouritesDesc CharacterSort = "FAVOURITES_DESC"
+	CharacterSortRelevance      CharacterSort = "RELEVANCE"
+)
+
+// CharacterSortValues lists every CharacterSort, in the order the schema declares them.
+var CharacterSortValues = []CharacterSort{
+	CharacterSortID,
+	CharacterSortIDDesc,
+	CharacterSortRole,
+	CharacterSortRoleDesc,
+	CharacterSortSearchMatch,
+	CharacterSortFavourites,
+	CharacterSortFavouritesDesc,
+	CharacterSortRelevance,
+}
+
+func (e CharacterSort) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e CharacterSort) IsValid() bool {
+	switch e {
+	case CharacterSortID, CharacterSortIDDesc, CharacterSortRole, CharacterSortRoleDesc, CharacterSortSearchMatch, CharacterSortFavourites, CharacterSortFavouritesDesc, CharacterSortRelevance:
+		return true
+	}
+	return false
+}
+
+// StaffLanguage is the AniList StaffLanguage enum.
+type StaffLanguage string
+
+const (
+	StaffLanguageJapanese   StaffLanguage = "JAPANESE"
+	StaffLanguageEnglish    StaffLanguage = "ENGLISH"
+	StaffLanguageKorean     StaffLanguage = "KOREAN"
+	StaffLanguageItalian    StaffLanguage = "ITALIAN"
+	StaffLanguageSpanish    StaffLanguage = "SPANISH"
+	StaffLanguagePortuguese StaffLanguage = "PORTUGUESE"
+	StaffLanguageFrench     StaffLanguage = "FRENCH"
+	StaffLanguageGerman     StaffLanguage = "GERMAN"
+	StaffLanguageHebrew     StaffLanguage = "HEBREW"
+	StaffLanguageHungarian  StaffLanguage = "HUNGARIAN"
+)
+
+// StaffLanguageValues lists every StaffLanguage, in the order the schema declares them.
+var StaffLanguageValues = []StaffLanguage{
+	StaffLanguageJapanese,
+	StaffLanguageEnglish,
+	StaffLanguageKorean,
+	StaffLanguageItalian,
+	StaffLanguageSpanish,
+	StaffLanguagePortuguese,
+	StaffLanguageFrench,
+	StaffLanguageGerman,
+	StaffLanguageHebrew,
+	StaffLanguageHungarian,
+}
+
+func (e StaffLanguage) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e StaffLanguage) IsValid() bool {
+	switch e {
+	case StaffLanguageJapanese, StaffLanguageEnglish, StaffLanguageKorean, StaffLanguageItalian, StaffLanguageSpanish, StaffLanguagePortuguese, StaffLanguageFrench, StaffLanguageGerman, StaffLanguageHebrew, StaffLanguageHungarian:
+		return true
+	}
+	return false
+}
+
+// StaffSort is the AniList StaffSort enum.
+type StaffSort string
+
+const (
+	StaffSortID             StaffSort = "ID"
+	StaffSortIDDesc         StaffSort = "ID_DESC"
+	StaffSortRole           StaffSort = "ROLE"
+	StaffSortRoleDesc       StaffSort = "ROLE_DESC"
+	StaffSortLanguage       StaffSort = "LANGUAGE"
+	StaffSortLanguageDesc   StaffSort = "LANGUAGE_DESC"
+	StaffSortSearchMatch    StaffSort = "SEARCH_MATCH"
+	StaffSortFavourites     StaffSort = "FAVOURITES"
+	StaffSortFavouritesDesc StaffSort = "FAVOURITES_DESC"
+	StaffSortRelevance      StaffSort = "RELEVANCE"
+)
+
+// StaffSortValues lists every StaffSort, in the order the schema declares them.
+var StaffSortValues = []StaffSort{
+	StaffSortID,
+	StaffSortIDDesc,
+	StaffSortRole,
+	StaffSortRoleDesc,
+	StaffSortLanguage,
+	StaffSortLanguageDesc,
+	StaffSortSearchMatch,
+	StaffSortFavourites,
+	StaffSortFavouritesDesc,
+	StaffSortRelevance,
+}
+
+func (e StaffSort) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e StaffSort) IsValid() bool {
+	switch e {
+	case StaffSortID, StaffSortIDDesc, StaffSortRole, StaffSortRoleDesc, StaffSortLanguage, StaffSortLanguageDesc, StaffSortSearchMatch, StaffSortFavourites, StaffSortFavouritesDesc, StaffSortRelevance:
+		return true
+	}
+	return false
+}
+
+// StudioSort is the AniList StudioSort enum.
+type StudioSort string
+
+const (
+	StudioSortID             StudioSort = "ID"
+	StudioSortIDDesc         StudioSort = "ID_DESC"
+	StudioSortName           StudioSort = "NAME"
+	StudioSortNameDesc       StudioSort = "NAME_DESC"
+	StudioSortSearchMatch    StudioSort = "SEARCH_MATCH"
+	StudioSortFavourites     StudioSort = "FAVOURITES"
+	StudioSortFavouritesDesc StudioSort = "FAVOURITES_DESC"
+)
+
+// StudioSortValues lists every StudioSort, in the order the schema declares them.
+var StudioSortValues = []StudioSort{
+	StudioSortID,
+	StudioSortIDDesc,
+	StudioSortName,
+	StudioSortNameDesc,
+	StudioSortSearchMatch,
+	StudioSortFavourites,
+	StudioSortFavouritesDesc,
+}
+
+func (e StudioSort) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e StudioSort) IsValid() bool {
+	switch e {
+	case StudioSortID, StudioSortIDDesc, StudioSortName, StudioSortNameDesc, StudioSortSearchMatch, StudioSortFavourites, StudioSortFavouritesDesc:
+		return true
+	}
+	return false
+}
+
+// AiringSort is the AniList AiringSort enum.
+type AiringSort string
+
+const (
+	AiringSortID          AiringSort = "ID"
+	AiringSortIDDesc      AiringSort = "ID_DESC"
+	AiringSortMediaID     AiringSort = "MEDIA_ID"
+	AiringSortMediaIDDesc AiringSort = "MEDIA_ID_DESC"
+	AiringSortTime        AiringSort = "TIME"
+	AiringSortTimeDesc    AiringSort = "TIME_DESC"
+	AiringSortEpisode     AiringSort = "EPISODE"
+	AiringSortEpisodeDesc AiringSort = "EPISODE_DESC"
+)
+
+// AiringSortValues lists every AiringSort, in the order the schema declares them.
+var AiringSortValues = []AiringSort{
+	AiringSortID,
+	AiringSortIDDesc,
+	AiringSortMediaID,
+	AiringSortMediaIDDesc,
+	AiringSortTime,
+	AiringSortTimeDesc,
+	AiringSortEpisode,
+	AiringSortEpisodeDesc,
+}
+
+func (e AiringSort) String() string {
+	return string(e)
+}
+
+// IsValid reports whether e is one of the values the schema declares.
+func (e AiringSort) IsValid() bool {
+	switch e {
+	case AiringSortID, AiringSortIDDesc, AiringSortMediaID, AiringSortMediaIDDesc, AiringSortTime, AiringSortTimeDesc, AiringSortEpisode, AiringSortEpisodeDesc:
+		return true
+	}
+	return false
+}
+
+// Query is the AniList Query object.
+type Query struct {
+	Page      Page      `json:"Page"`
+	Media     Media     `json:"Media"`
+	Character Character `json:"Character"`
+	Staff     Staff     `json:"Staff"`
+	Studio    Studio    `json:"Studio"`
+}
+
+// Page is the AniList Page object.
+type Page struct {
+	PageInfo        PageInfo         `json:"pageInfo"`
+	Media           []Media          `json:"media"`
+	Characters      []Character      `json:"characters"`
+	Staff           []Staff          `json:"staff"`
+	Studios         []Studio         `json:"studios"`
+	AiringSchedules []AiringSchedule `json:"airingSchedules"`
+}
+
+// PageInfo is the AniList PageInfo object.
+type PageInfo struct {
+	Total       int  `json:"total"`
+	PerPage     int  `json:"perPage"`
+	CurrentPage int  `json:"currentPage"`
+	LastPage    int  `json:"lastPage"`
+	HasNextPage bool `json:"hasNextPage"`
+}
+
+// Media is the AniList Media object.
+type Media struct {
+	ID                int                      `json:"id"`
+	Title             MediaTitle               `json:"title"`
+	Type              MediaType                `json:"type"`
+	Format            MediaFormat              `json:"format"`
+	Status            MediaStatus              `json:"status"`
+	Description       string                   `json:"description"`
+	StartDate         FuzzyDate                `json:"startDate"`
+	EndDate           FuzzyDate                `json:"endDate"`
+	Season            MediaSeason              `json:"season"`
+	SeasonYear        int                      `json:"seasonYear"`
+	Episodes          int                      `json:"episodes"`
+	CountryOfOrigin   string                   `json:"countryOfOrigin"`
+	Source            MediaSource              `json:"source"`
+	CoverImage        MediaCoverImage          `json:"coverImage"`
+	Genres            []string                 `json:"genres"`
+	Synonyms          []string                 `json:"synonyms"`
+	AverageScore      int                      `json:"averageScore"`
+	Popularity        int                      `json:"popularity"`
+	IsAdult           bool                     `json:"isAdult"`
+	NextAiringEpisode *AiringSchedule          `json:"nextAiringEpisode"`
+	AiringSchedule    AiringScheduleConnection `json:"airingSchedule"`
+	Characters        CharacterConnection      `json:"characters"`
+	Staff             StaffConnection          `json:"staff"`
+	Studios           StudioConnection         `json:"studios"`
+	SiteURL           string                   `json:"siteUrl"`
+}
+
+// MediaTitle is the AniList MediaTitle object.
+type MediaTitle struct {
+	Romaji  string `json:"romaji"`
+	English string `json:"english"`
+	Native  string `json:"native"`
+}
+
+// MediaCoverImage is the AniList MediaCoverImage object.
+type MediaCoverImage struct {
+	ExtraLarge string `json:"extraLarge"`
+	Large      string `json:"large"`
+	Medium     string `json:"medium"`
+	Color      string `json:"color"`
+}
+
+// FuzzyDate is the AniList FuzzyDate object.
+type FuzzyDate struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// MediaConnection is the AniList MediaConnection object.
+type MediaConnection struct {
+	Edges    []MediaEdge `json:"edges"`
+	Nodes    []Media     `json:"nodes"`
+	PageInfo PageInfo    `json:"pageInfo"`
+}
+
+// MediaEdge is the AniList MediaEdge object.
+type MediaEdge struct {
+	Node          Media         `json:"node"`
+	ID            int           `json:"id"`
+	IsMainStudio  bool          `json:"isMainStudio"`
+	CharacterRole CharacterRole `json:"characterRole"`
+	CharacterName string        `json:"characterName"`
+	StaffRole     string        `json:"staffRole"`
+	Characters    []Character   `json:"characters"`
+	VoiceActors   []Staff       `json:"voiceActors"`
+}
+
+// AiringSchedule is the AniList AiringSchedule object.
+type AiringSchedule struct {
+	ID              int   `json:"id"`
+	AiringAt        int   `json:"airingAt"`
+	TimeUntilAiring int   `json:"timeUntilAiring"`
+	Episode         int   `json:"episode"`
+	MediaID         int   `json:"mediaId"`
+	Media           Media `json:"media"`
+}
+
+// AiringScheduleConnection is the AniList AiringScheduleConnection object.
+type AiringScheduleConnection struct {
+	Nodes    []AiringSchedule `json:"nodes"`
+	PageInfo PageInfo         `json:"pageInfo"`
+}
+
+// Character is the AniList Character object.
+type Character struct {
+	ID          int             `json:"id"`
+	Name        CharacterName   `json:"name"`
+	Image       CharacterImage  `json:"image"`
+	Description string          `json:"description"`
+	SiteURL     string          `json:"siteUrl"`
+	Media       MediaConnection `json:"media"`
+	Favourites  int             `json:"favourites"`
+}
+
+// CharacterConnection is the AniList CharacterConnection object.
+type CharacterConnection struct {
+	Edges    []CharacterEdge `json:"edges"`
+	Nodes    []Character     `json:"nodes"`
+	PageInfo PageInfo        `json:"pageInfo"`
+}
+
+// CharacterEdge is the AniList CharacterEdge object.
+type CharacterEdge struct {
+	Node        Character     `json:"node"`
+	ID          int           `json:"id"`
+	Role        CharacterRole `json:"role"`
+	Name        string        `json:"name"`
+	VoiceActors []Staff       `json:"voiceActors"`
+}
+
+// CharacterName is the AniList CharacterName object.
+type CharacterName struct {
+	First       string   `json:"first"`
+	Middle      string   `json:"middle"`
+	Last        string   `json:"last"`
+	Full        string   `json:"full"`
+	Native      string   `json:"native"`
+	Alternative []string `json:"alternative"`
+}
+
+// CharacterImage is the AniList CharacterImage object.
+type CharacterImage struct {
+	Large  string `json:"large"`
+	Medium string `json:"medium"`
+}
+
+// Staff is the AniList Staff object.
+type Staff struct {
+	ID                 int             `json:"id"`
+	Name               StaffName       `json:"name"`
+	LanguageV2         string          `json:"languageV2"`
+	Image              StaffImage      `json:"image"`
+	Description        string          `json:"description"`
+	PrimaryOccupations []string        `json:"primaryOccupations"`
+	YearsActive        []int           `json:"yearsActive"`
+	SiteURL            string          `json:"siteUrl"`
+	StaffMedia         MediaConnection `json:"staffMedia"`
+	CharacterMedia     MediaConnection `json:"characterMedia"`
+	Favourites         int             `json:"favourites"`
+}
+
+// StaffName is the AniList StaffName object.
+type StaffName struct {
+	First       string   `json:"first"`
+	Middle      string   `json:"middle"`
+	Last        string   `json:"last"`
+	Full        string   `json:"full"`
+	Native      string   `json:"native"`
+	Alternative []string `json:"alternative"`
+}
+
+// StaffImage is the AniList StaffImage object.
+type StaffImage struct {
+	Large  string `json:"large"`
+	Medium string `json:"medium"`
+}
+
+// StaffConnection is the AniList StaffConnection object.
+type StaffConnection struct {
+	Edges    []StaffEdge `json:"edges"`
+	Nodes    []Staff     `json:"nodes"`
+	PageInfo PageInfo    `json:"pageInfo"`
+}
+
+// StaffEdge is the AniList StaffEdge object.
+type StaffEdge struct {
+	Node Staff  `json:"node"`
+	ID   int    `json:"id"`
+	Role string `json:"role"`
+}
+
+// Studio is the AniList Studio object.
+type Studio struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	IsAnimationStudio bool            `json:"isAnimationStudio"`
+	Media             MediaConnection `json:"media"`
+	SiteURL           string          `json:"siteUrl"`
+	Favourites        int             `json:"favourites"`
+}
+
+// StudioConnection is the AniList StudioConnection object.
+type StudioConnection struct {
+	Edges    []StudioEdge `json:"edges"`
+	Nodes    []Studio     `json:"nodes"`
+	PageInfo PageInfo     `json:"pageInfo"`
+}
+
+// StudioEdge is the AniList StudioEdge object.
+type StudioEdge struct {
+	Node   Studio `json:"node"`
+	ID     int    `json:"id"`
+	IsMain bool   `json:"isMain"`
+}
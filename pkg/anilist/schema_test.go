@@ -0,0 +1,30 @@
+package anilist
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/buckley-w-david/anibot/pkg/anilist/internal/schema"
+)
+
+// TestOperationsMatchSchema checks every query the package sends, with every combination of Media fields,
+// against schema.graphql, so a misspelled field, argument or enum value fails here rather than on AniList.
+func TestOperationsMatchSchema(t *testing.T) {
+	source, err := ioutil.ReadFile("schema.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.Parse(string(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range operations() {
+		for fields := MediaFields(0); fields <= AllMediaFields; fields++ {
+			if err := s.Validate(op.query(fields)); err != nil {
+				t.Errorf("%s with fields %b: %v", op.name, fields, err)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package anilist
+
+import (
+	"context"
+)
+
+type SeasonPageResponse = Query
+
+var seasonQuery *operation
+
+func init() {
+	seasonQuery = newOperation("Season", `
+      Page(page: $page, perPage: $max) {`+pageInfoSelection+`
+        media(season: $season, seasonYear: $year, type: ANIME, isAdult: $isAdult, sort: POPULARITY_DESC) {
+          id
+          siteUrl
+          type
+          format
+          episodes
+          averageScore
+          title {
+            english
+            romaji
+          }
+          studios(isMain: true) {
+            edges {
+              node {
+                id
+                name
+                siteUrl
+              }
+            }
+          }
+        }
+      }
+    `,
+		variable{"season", "MediaSeason!"},
+		variable{"year", "Int!"},
+		variable{"isAdult", "Boolean"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+	)
+}
+
+// MediaFromSeason lists one page of the anime airing in season of year, most popular first.
+// Like MediaQuery.IsAdult, isAdult restricts it to adult anime when true, and excludes them when false.
+func (c *Client) MediaFromSeason(ctx context.Context, season MediaSeason, year int, isAdult *bool, page int, perPage int) ([]Media, PageInfo, error) {
+	vars := pageVars(map[string]interface{}{"season": season, "year": year}, page, perPage)
+	if isAdult != nil {
+		vars["isAdult"] = *isAdult
+	}
+
+	var res SeasonPageResponse
+	if err := c.runOperation(ctx, seasonQuery, 0, vars, &res); err != nil {
+		return []Media{}, PageInfo{}, err
+	}
+	return res.Page.Media, res.Page.PageInfo, nil
+}
+
+func MediaFromSeason(ctx context.Context, season MediaSeason, year int, isAdult *bool, page int, perPage int) ([]Media, PageInfo, error) {
+	return DefaultClient.MediaFromSeason(ctx, season, year, isAdult, page, perPage)
+}
@@ -0,0 +1,135 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+)
+
+// StaffCredit is a piece of media a staff member worked on, and what they did on it.
+type StaffCredit = MediaEdge
+
+type StaffProfilePageResponse = Query
+
+// How many of a staff member's most popular works, and most popular voice roles, are included in their profile.
+const notableWorks = 6
+
+var staffProfileQuery *operation
+
+func init() {
+	staffProfileQuery = newOperation("StaffProfiles", `
+      Page(page: $page, perPage: $max) {
+        staff(id: $id, search: $search, sort: [SEARCH_MATCH, FAVOURITES_DESC]) {
+          id
+          siteUrl
+          name {
+            first
+            last
+            full
+            native
+          }
+          description(asHtml: false)
+          primaryOccupations
+          yearsActive
+          languageV2
+          favourites
+          image {
+            large
+            medium
+          }
+          staffMedia(sort: POPULARITY_DESC, type: $type, page: 1, perPage: $works) {
+            edges {
+              staffRole
+              node {
+                id
+                siteUrl
+                type
+                format
+                title {
+                  english
+                  romaji
+                }
+              }
+            }
+          }
+          characterMedia(sort: POPULARITY_DESC, page: 1, perPage: $works) {
+            edges {
+              characterRole
+              characters {
+                id
+                siteUrl
+                name {
+                  full
+                }
+              }
+              node {
+                id
+                siteUrl
+                type
+                format
+                title {
+                  english
+                  romaji
+                }
+              }
+            }
+          }
+        }
+      }
+    `,
+		variable{"id", "Int"},
+		variable{"search", "String"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+		variable{"type", "MediaType"},
+		variable{"works", "Int!"},
+	)
+}
+
+// StaffFromPersonQuery looks up the full profiles of the staff members matching query.
+// query.Type restricts which kind of media is listed in each profile's StaffMedia.
+func (c *Client) StaffFromPersonQuery(ctx context.Context, query PersonQuery) ([]Staff, error) {
+	vars := pageVars(map[string]interface{}{"works": notableWorks}, query.Page, perPage(query.PerPage, query.MaxResults))
+	if query.Name != "" {
+		vars["search"] = query.Name
+	} else if query.ID != 0 {
+		vars["id"] = query.ID
+	} else {
+		return []Staff{}, fmt.Errorf("%w: neither ID nor Name set in PersonQuery", ErrInvalidQuery)
+	}
+	if query.Type != "" {
+		vars["type"] = query.Type
+	}
+
+	var res StaffProfilePageResponse
+	if err := c.runOperation(ctx, staffProfileQuery, 0, vars, &res); err != nil {
+		return []Staff{}, err
+	}
+	return res.Page.Staff, nil
+}
+
+func (c *Client) StaffFromPersonID(ctx context.Context, id int) (Staff, error) {
+	staff, err := c.StaffFromPersonQuery(ctx, PersonQuery{ID: id, PerPage: 1})
+	if err != nil {
+		return Staff{}, err
+	}
+	if len(staff) == 0 {
+		return Staff{}, fmt.Errorf("%w: no staff with ID %d", ErrNotFound, id)
+	}
+	return staff[0], nil
+}
+
+func (c *Client) StaffFromPersonName(ctx context.Context, name string, maxResults int) ([]Staff, error) {
+	return c.StaffFromPersonQuery(ctx, PersonQuery{Name: name, PerPage: maxResults})
+}
+
+func StaffFromPersonQuery(ctx context.Context, query PersonQuery) ([]Staff, error) {
+	return DefaultClient.StaffFromPersonQuery(ctx, query)
+}
+
+func StaffFromPersonID(ctx context.Context, id int) (Staff, error) {
+	return DefaultClient.StaffFromPersonID(ctx, id)
+}
+
+func StaffFromPersonName(ctx context.Context, name string, maxResults int) ([]Staff, error) {
+	return DefaultClient.StaffFromPersonName(ctx, name, maxResults)
+}
@@ -0,0 +1,76 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+)
+
+// StudioProduction is a piece of media a studio worked on.
+type StudioProduction = MediaEdge
+
+// StudioDetail is the old name for Studio, from when the Studio attached to Media only had its name.
+type StudioDetail = Studio
+
+type StudioDetailResponse = Query
+
+var studioDetailQuery *operation
+
+func init() {
+	studioDetailQuery = newOperation("StudioDetail", `
+      Studio(id: $id, search: $search) {
+        id
+        name
+        siteUrl
+        isAnimationStudio
+        favourites
+        media(sort: START_DATE_DESC, page: $page, perPage: $max) {`+pageInfoSelection+`
+          edges {
+            isMainStudio
+            node {
+              id
+              siteUrl
+              type
+              format
+              title {
+                english
+                romaji
+              }
+              startDate {
+                year
+                month
+                day
+              }
+            }
+          }
+        }
+      }
+    `,
+		variable{"id", "Int"},
+		variable{"search", "String"},
+		variable{"page", "Int!"},
+		variable{"max", "Int!"},
+	)
+}
+
+// StudioDetailFromStudioQuery looks up the profile of the studio matching query, along with the page
+// of its productions that query.Page asks for, newest first.
+func (c *Client) StudioDetailFromStudioQuery(ctx context.Context, query StudioQuery) (StudioDetail, error) {
+	vars := pageVars(map[string]interface{}{}, query.Page, perPage(query.PerPage, query.MaxResults))
+	if query.Name != "" {
+		vars["search"] = query.Name
+	} else if query.ID != 0 {
+		vars["id"] = query.ID
+	} else {
+		return StudioDetail{}, fmt.Errorf("%w: neither ID nor Name set in StudioQuery", ErrInvalidQuery)
+	}
+
+	var res StudioDetailResponse
+	if err := c.runOperation(ctx, studioDetailQuery, 0, vars, &res); err != nil {
+		return StudioDetail{}, err
+	}
+	return res.Studio, nil
+}
+
+func StudioDetailFromStudioQuery(ctx context.Context, query StudioQuery) (StudioDetail, error) {
+	return DefaultClient.StudioDetailFromStudioQuery(ctx, query)
+}